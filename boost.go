@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// hostBoost multiplies the score of URLs
+// whose host matches a glob pattern
+type hostBoost struct {
+	pattern    string
+	multiplier float64
+}
+
+// hostBoosts implements flag.Value so that -boost-host
+// can be given more than once
+type hostBoosts []hostBoost
+
+func (b *hostBoosts) String() string {
+	bb := make([]string, len(*b))
+	for i, hb := range *b {
+		bb[i] = fmt.Sprintf("%s:%g", hb.pattern, hb.multiplier)
+	}
+	return strings.Join(bb, ",")
+}
+
+// Set parses a pattern:multiplier pair, e.g. *.internal.example.com:2
+func (b *hostBoosts) Set(v string) error {
+	i := strings.LastIndex(v, ":")
+	if i < 1 {
+		return fmt.Errorf("boost must be pattern:multiplier")
+	}
+
+	pattern := strings.ToLower(v[:i])
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid host pattern %s: %s", pattern, err)
+	}
+
+	m, err := strconv.ParseFloat(v[i+1:], 64)
+	if err != nil || m <= 0 {
+		return fmt.Errorf("invalid multiplier %s", v[i+1:])
+	}
+
+	*b = append(*b, hostBoost{pattern, m})
+	return nil
+}
+
+// multiplier returns the highest multiplier of all the patterns
+// matching host, or 1 if none of them match
+func (b hostBoosts) multiplier(host string) float64 {
+	host = strings.ToLower(host)
+
+	best := 0.0
+	for _, hb := range b {
+		if ok, _ := path.Match(hb.pattern, host); ok && hb.multiplier > best {
+			best = hb.multiplier
+		}
+	}
+
+	if best == 0 {
+		return 1
+	}
+	return best
+}
+
+// apply returns score multiplied by the boost for host
+// along with the multiplier that was used
+func (b hostBoosts) apply(score int, host string) (int, float64) {
+	m := b.multiplier(host)
+	return int(math.Round(float64(score) * m)), m
+}
@@ -0,0 +1,2268 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// urlCheck is a single named heuristic. When a check matches
+// a URL its weight is added to that URL's score.
+type urlCheck struct {
+	name   string
+	weight int
+
+	// desc is a short description of what
+	// the check looks for, for -list-checks
+	desc string
+
+	// fn looks at the URL as a whole and returns some detail
+	// about what it matched, param looks at each key=value pair
+	// in the query string (and any matrix parameters) in turn.
+	// value is like param but also returns the detail for that
+	// parameter. Only one of fn, param and value should be set,
+	// except that checks using fn can also set value to say which
+	// parameters they match (e.g. for -fuzz-format).
+	fn    func(*url.URL) (string, bool)
+	param func(k, v string) bool
+	value func(k, v string) (string, bool)
+
+	// needsHost marks checks that only make sense for URLs with a
+	// host; they're skipped for path-only input like /admin?id=1
+	needsHost bool
+
+	// off marks checks that are too noisy to run by default;
+	// they have to be switched on with enableCheck
+	off bool
+}
+
+// match returns true if the check matches the URL, along with
+// some detail about what matched. For param checks the detail
+// is the list of matching parameter names.
+func (c urlCheck) match(u *url.URL) (string, bool) {
+	switch {
+	case c.fn != nil:
+		return c.fn(u)
+	case c.value != nil:
+		return matchValues(u, c.value)
+	}
+
+	keys := make([]string, 0)
+	for k, vv := range params(u) {
+		for _, v := range vv {
+			if c.param(k, v) {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, ","), len(keys) > 0
+}
+
+// matches is like match but doesn't bother working out the detail,
+// so param checks can stop at the first matching parameter
+func (c urlCheck) matches(u *url.URL) bool {
+	if c.fn != nil {
+		_, ok := c.fn(u)
+		return ok
+	}
+
+	for k, vv := range params(u) {
+		for _, v := range vv {
+			if c.matchesParam(k, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesParam returns true if the check matches a single key=value
+// pair. It's always false for checks that only look at the whole URL.
+func (c urlCheck) matchesParam(k, v string) bool {
+	if c.param != nil {
+		return c.param(k, v)
+	}
+	if c.value != nil {
+		_, ok := c.value(k, v)
+		return ok
+	}
+	return false
+}
+
+// matchValues runs a value check against every parameter in a URL,
+// returning the sorted details for the first matching value of each
+func matchValues(u *url.URL, value func(k, v string) (string, bool)) (string, bool) {
+	found := make([]string, 0)
+
+	for k, vv := range params(u) {
+		for _, v := range vv {
+			if d, ok := value(k, v); ok {
+				found = append(found, d)
+				break
+			}
+		}
+	}
+	sort.Strings(found)
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// interestingParams returns the sorted names of the query
+// string parameters in a URL that any per-parameter check matches
+func interestingParams(u *url.URL) []string {
+	keys := make([]string, 0)
+
+	for k, vv := range u.Query() {
+	outer:
+		for _, c := range checks {
+			if c.off {
+				continue
+			}
+
+			for _, v := range vv {
+				if c.matchesParam(k, v) {
+					keys = append(keys, k)
+					break outer
+				}
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// params returns the query string parameters of a URL along
+// with any matrix parameters in its path
+func params(u *url.URL) url.Values {
+	pp := u.Query()
+	for k, vv := range matrixParams(u) {
+		pp[k] = append(pp[k], vv...)
+	}
+	return pp
+}
+
+// matrixParams returns the matrix parameters from a URL's path.
+// Some frameworks (e.g. JAX-RS) put parameters in path segments
+// like /users;role=admin;active=true/list, which url.Parse just
+// leaves in the path.
+func matrixParams(u *url.URL) url.Values {
+	pp := make(url.Values)
+
+	for _, seg := range strings.Split(u.EscapedPath(), "/") {
+		parts := strings.Split(seg, ";")
+		for _, part := range parts[1:] {
+			if part == "" {
+				continue
+			}
+
+			k, v := part, ""
+			if i := strings.Index(part, "="); i != -1 {
+				k, v = part[:i], part[i+1:]
+			}
+
+			if dk, err := url.PathUnescape(k); err == nil {
+				k = dk
+			}
+			if dv, err := url.PathUnescape(v); err == nil {
+				v = dv
+			}
+
+			pp[k] = append(pp[k], v)
+		}
+	}
+
+	return pp
+}
+
+// reason records a check that matched a URL
+type reason struct {
+	check  string
+	detail string
+}
+
+func (r reason) String() string {
+	if r.detail == "" {
+		return r.check
+	}
+	return r.check + "(" + r.detail + ")"
+}
+
+var checks = []urlCheck{
+	{name: "query-params", weight: 1, param: qsCheck, desc: "parameters with interesting names or values"},
+	{name: "extensions", weight: 1, fn: extensionsCheck, desc: "interesting file extensions, e.g. .php or .json"},
+	{name: "sensitive-paths", weight: 1, fn: sensitivePathsCheck, desc: "interesting words in the path, e.g. admin or proxy"},
+	{name: "non-standard-port", weight: 1, fn: nonStandardPortCheck, needsHost: true, desc: "a port that isn't the default for the scheme"},
+	{name: "debug-endpoint", weight: 3, fn: debugEndpointCheck, desc: "debugging and profiling endpoints, e.g. /actuator/env"},
+	{name: "time-based-payload", weight: 2, param: timeBasedPayloadCheck, desc: "time delays used in blind injection payloads"},
+	{name: "interesting-filename", weight: 1, fn: interestingFilenameCheck, desc: "well-known recon files, e.g. robots.txt or phpinfo.php"},
+	{name: "embedded-request-data", weight: 2, param: embeddedRequestDataCheck, desc: "parameters carrying cookies or headers"},
+	{name: "alt-encoding", weight: 1, value: altEncodingCheck, desc: "base32 or hex encoded payloads in parameters"},
+	{name: "cors-surface", weight: 1, param: corsSurfaceCheck, desc: "origin and JSONP callback parameters"},
+	{name: "admin-panel", weight: 2, fn: adminPanelCheck, desc: "admin and login pages of well-known CMSs and frameworks"},
+	{name: "info-disclosure", weight: 2, param: infoDisclosureCheck, desc: "parameters that switch on diagnostic output"},
+	{name: "redirect-control", weight: 1, param: redirectControlCheck, desc: "parameters that control the type of a redirect"},
+	{name: "mime-control", weight: 1, param: mimeControlCheck, desc: "parameters that set a file's type or extension"},
+	{name: "nested-ssrf", weight: 2, param: nestedSSRFCheck, desc: "URLs in parameters that lead on to an internal host"},
+	{name: "ua-param", weight: 1, param: uaParamCheck, desc: "user agent parameters and bot or scanner names"},
+	{name: "email-injection", weight: 1, param: emailInjectionCheck, desc: "parameters that suggest the endpoint sends email"},
+	{name: "email-injection-payload", weight: 1, param: emailInjectionPayloadCheck, desc: "email parameters with line breaks or template syntax"},
+	{name: "serialized-state", weight: 2, value: serializedStateCheck, desc: "opaque serialized state, e.g. __VIEWSTATE"},
+	{name: "high-entropy", weight: 2, value: highEntropyCheck, off: true, desc: "long, random-looking parameter values"},
+	{name: "rest-action", weight: 2, fn: restActionCheck, desc: "state-changing REST actions, e.g. /users/1/delete"},
+	{name: "json-query-param", weight: 2, param: jsonQueryParamCheck, desc: "JSON parameter values with query operators, e.g. $where"},
+	{name: "horizontal-authz", weight: 2, param: horizontalAuthzCheck, desc: "parameters that scope a request to a user or account"},
+	{name: "security-downgrade", weight: 2, param: securityDowngradeCheck, desc: "parameters that turn off a security feature"},
+	{name: "geo-enum", weight: 1, param: geoEnumCheck, desc: "coordinates and postcodes in parameters"},
+	{name: "waf-bypass", weight: 1, fn: wafBypassCheck, desc: "tricks for getting payloads past a WAF"},
+	{name: "query-composite", weight: 1, fn: queryCompositeCheck, desc: "long or busy query strings"},
+	{name: "matrix-params", weight: 1, fn: matrixParamsCheck, desc: "matrix parameters in the path, e.g. /users;role=admin"},
+	{name: "sqli-in-path", weight: 2, fn: sqliInPathCheck, desc: "SQL injection payloads in the path"},
+	{name: "internal-api-ref", weight: 2, param: internalAPIRefCheck, desc: "routing parameters pointing at internal API paths"},
+	{name: "absolute-path-value", weight: 2, value: absolutePathValueCheck, desc: "absolute filesystem paths in parameters"},
+	{name: "presigned-url", weight: 2, fn: presignedURLCheck, desc: "cloud storage pre-signed URL parameters"},
+	{name: "polyglot-payload", weight: 2, value: polyglotPayloadCheck, desc: "payloads built to work in several injection contexts"},
+	{name: "smuggling-indicator", weight: 2, fn: smugglingIndicatorCheck, desc: "request smuggling artifacts, e.g. transfer-encoding params or CRLFs in the path"},
+	{name: "sensitive-value-format", weight: 2, value: sensitiveValueFormatCheck, desc: "values formatted like card numbers, SSNs, phone numbers, private keys or bearer tokens"},
+	{name: "expression-eval", weight: 1, param: expressionEvalCheck, desc: "arithmetic template probes like {{7*7}} and their results"},
+	{name: "framework-exploit", weight: 3, fn: frameworkExploitCheck, desc: "known framework RCE and traversal surface, e.g. Struts OGNL or Spring4Shell"},
+	{name: "ssrf-dns-service", weight: 2, fn: ssrfDNSServiceCheck, desc: "hosts on SSRF testing DNS services, e.g. nip.io or interactsh"},
+	{name: "feature-flag", weight: 1, param: featureFlagCheck, desc: "parameters named like feature flags or experiments"},
+	{name: "container-metadata", weight: 3, fn: containerMetadataCheck, desc: "references to Docker and Kubernetes internals, e.g. docker.sock"},
+	{name: "ldap-injection", weight: 2, param: ldapInjectionCheck, desc: "LDAP filter metacharacters in values, or LDAP-ish parameter names"},
+	{name: "control-char", weight: 2, fn: controlCharCheck, desc: "encoded control characters like backspace or escape anywhere in the URL"},
+	{name: "ssi-injection", weight: 2, fn: ssiInjectionCheck, value: ssiDirectiveCheck, desc: "server-side include directives in values, or SSI file extensions"},
+	{name: "admin-action", weight: 3, fn: adminActionCheck, desc: "admin paths with state-changing action or target ID parameters"},
+	{name: "key-smuggling", weight: 2, fn: keySmugglingCheck, desc: "differently written parameter names that normalize to the same name"},
+}
+
+// enableCheck switches on the named check
+func enableCheck(name string) {
+	for i := range checks {
+		if checks[i].name == name {
+			checks[i].off = false
+		}
+	}
+}
+
+// disableCheck switches off the named check
+func disableCheck(name string) {
+	for i := range checks {
+		if checks[i].name == name {
+			checks[i].off = true
+		}
+	}
+}
+
+// setCheckWeight changes the weight of the named check
+func setCheckWeight(name string, weight int) {
+	for i := range checks {
+		if checks[i].name == name {
+			checks[i].weight = weight
+		}
+	}
+}
+
+// isCheck returns true if there's a check called name
+func isCheck(name string) bool {
+	for _, c := range checks {
+		if c.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// listChecks writes the name, default weight, default state (on or
+// off) and description of every check, one per line and tab separated
+func listChecks(w io.Writer) {
+	for _, c := range checks {
+		state := "on"
+		if c.off {
+			state = "off"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", c.name, c.weight, state, c.desc)
+	}
+}
+
+// score runs every check against a URL and returns the
+// sum of the weights of the checks that matched, along
+// with the reasons they matched
+func score(u *url.URL) (int, []reason) {
+	total := 0
+	reasons := make([]reason, 0)
+
+	for _, c := range checks {
+		if c.off || (c.needsHost && u.Host == "") {
+			continue
+		}
+
+		if detail, ok := c.match(u); ok {
+			total += c.weight
+			reasons = append(reasons, reason{c.name, detail})
+		}
+	}
+
+	return total, reasons
+}
+
+// byWeight returns a copy of cc with the heaviest checks first.
+// Checks with the same weight stay in the order they were in.
+//
+// That order lets reachesScore get to a high min in fewer checks,
+// but the heavy checks tend to be the slow ones, so it's only used
+// with -order-checks. BenchmarkReachesScore compares the two.
+func byWeight(cc []urlCheck) []urlCheck {
+	out := make([]urlCheck, len(cc))
+	copy(out, cc)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].weight > out[j].weight
+	})
+	return out
+}
+
+// reachesScore returns true if a URL scores at least min. It's a
+// faster alternative to score for when only the filtered URLs are
+// needed: it stops as soon as min is reached and doesn't keep track
+// of the reasons. It also gives up as soon as min is out of reach.
+// The checks are run in the order given (see byWeight). Weights
+// must not be negative.
+func reachesScore(u *url.URL, min int, cc []urlCheck) bool {
+	total := 0
+	if total >= min {
+		return true
+	}
+
+	// The most the URL could still score if every
+	// remaining check matched
+	left := 0
+	for _, c := range cc {
+		if !c.off && !(c.needsHost && u.Host == "") {
+			left += c.weight
+		}
+	}
+
+	for _, c := range cc {
+		if c.off || (c.needsHost && u.Host == "") {
+			continue
+		}
+
+		left -= c.weight
+		if c.matches(u) {
+			total += c.weight
+			if total >= min {
+				return true
+			}
+		}
+
+		if total+left < min {
+			return false
+		}
+	}
+
+	return false
+}
+
+// extensions
+func extensionsCheck(u *url.URL) (string, bool) {
+	exts := []string{
+		".php",
+		".phtml",
+		".asp",
+		".aspx",
+		".asmx",
+		".ashx",
+		".cgi",
+		".pl",
+		".json",
+		".xml",
+		".rb",
+		".py",
+		".sh",
+		".yaml",
+		".yml",
+		".toml",
+		".ini",
+		".md",
+		".mkd",
+		".do",
+		".jsp",
+		".jspa",
+	}
+
+	p := strings.ToLower(u.EscapedPath())
+	for _, e := range exts {
+		if strings.HasSuffix(p, e) {
+			return e, true
+		}
+	}
+
+	return "", false
+}
+
+// path bits
+func sensitivePathsCheck(u *url.URL) (string, bool) {
+	bits := []string{
+		"ajax",
+		"jsonp",
+		"admin",
+		"include",
+		"src",
+		"redirect",
+		"proxy",
+		"test",
+		"tmp",
+		"temp",
+	}
+
+	p := strings.ToLower(u.EscapedPath())
+	for _, b := range bits {
+		if strings.Contains(p, b) {
+			return b, true
+		}
+	}
+
+	return "", false
+}
+
+// defaultPorts maps URL schemes to their default port
+var defaultPorts = map[string]string{
+	"http":   "80",
+	"ws":     "80",
+	"https":  "443",
+	"wss":    "443",
+	"ftp":    "21",
+	"ftps":   "990",
+	"ssh":    "22",
+	"sftp":   "22",
+	"telnet": "23",
+	"gopher": "70",
+	"ldap":   "389",
+	"ldaps":  "636",
+}
+
+// isDefaultPort returns true if the URL has no port, or if its
+// port is the default for its scheme. Without a scheme we don't
+// know any better, so 80 and 443 are both considered standard.
+func isDefaultPort(u *url.URL) bool {
+	port := u.Port()
+	if port == "" {
+		return true
+	}
+
+	if def, ok := defaultPorts[strings.ToLower(u.Scheme)]; ok {
+		return port == def
+	}
+
+	return u.Scheme == "" && (port == "80" || port == "443")
+}
+
+// non-standard port
+func nonStandardPortCheck(u *url.URL) (string, bool) {
+	return u.Port(), !isDefaultPort(u)
+}
+
+// debugEndpointCheck looks for well-known debugging and
+// profiling interfaces. These tend to leak environment
+// variables, config and stack traces, and some of them
+// can be abused for code execution.
+func debugEndpointCheck(u *url.URL) (string, bool) {
+	endpoints := []string{
+		"/debug/pprof",
+		"/actuator/env",
+		"/actuator",
+		"/_debug",
+		"/__debug__",
+		"/trace.axd",
+		"/elmah.axd",
+		"/server-status",
+		"/server-info",
+		"/rails/info",
+	}
+
+	// Add a trailing slash so that we only match on whole
+	// path segments; e.g. /actuator but not /actuators
+	p := strings.ToLower(u.EscapedPath()) + "/"
+	for _, e := range endpoints {
+		if strings.Contains(p, e+"/") {
+			return e, true
+		}
+	}
+
+	return "", false
+}
+
+// interestingFilenameCheck looks for well-known files that are
+// worth a look during recon but don't necessarily have one of
+// the extensions we're interested in
+func interestingFilenameCheck(u *url.URL) (string, bool) {
+	names := []string{
+		"robots.txt",
+		"sitemap.xml",
+		"crossdomain.xml",
+		"clientaccesspolicy.xml",
+		".well-known/security.txt",
+		"security.txt",
+		"humans.txt",
+		"phpinfo",
+		"phpinfo.php",
+		"info.php",
+		"test.php",
+		"adminer",
+		"adminer.php",
+		"composer.json",
+		"composer.lock",
+		"package.json",
+	}
+
+	p := strings.ToLower(u.EscapedPath())
+	for _, n := range names {
+		if p == n || strings.HasSuffix(p, "/"+n) {
+			return n, true
+		}
+	}
+
+	return "", false
+}
+
+// qsCheck looks a key=value pair from a query
+// string and returns true if it looks interesting
+func qsCheck(k, v string) bool {
+	k = strings.ToLower(k)
+	v = strings.ToLower(v)
+
+	// the super-common utm_referrer etc
+	// are rarely interesting
+	if strings.HasPrefix(k, "utm_") {
+		return false
+	}
+
+	// value checks
+	return strings.HasPrefix(v, "http") ||
+		strings.Contains(v, "{") ||
+		strings.Contains(v, "[") ||
+		strings.Contains(v, "/") ||
+		strings.Contains(v, "\\") ||
+		strings.Contains(v, "<") ||
+		strings.Contains(v, "(") ||
+		// shoutout to liveoverflow ;)
+		strings.Contains(v, "eyj") ||
+
+		// key checks
+		strings.Contains(k, "redirect") ||
+		strings.Contains(k, "debug") ||
+		strings.Contains(k, "password") ||
+		strings.Contains(k, "passwd") ||
+		strings.Contains(k, "file") ||
+		strings.Contains(k, "fn") ||
+		strings.Contains(k, "template") ||
+		strings.Contains(k, "include") ||
+		strings.Contains(k, "require") ||
+		strings.Contains(k, "url") ||
+		strings.Contains(k, "uri") ||
+		strings.Contains(k, "src") ||
+		strings.Contains(k, "href") ||
+		strings.Contains(k, "func") ||
+		strings.Contains(k, "callback")
+}
+
+// timeBasedPayloadCheck looks for the time delays used
+// in blind injection payloads. They mean either that the
+// parameter is worth a blind SQLi test, or that somebody
+// has already been testing it.
+func timeBasedPayloadCheck(k, v string) bool {
+	// Payloads often have extra whitespace in them
+	// (e.g. 'waitfor  delay' or 'sleep (5)'), so get
+	// rid of all of it before looking
+	v = strings.Join(strings.Fields(strings.ToLower(v)), "")
+
+	return strings.Contains(v, "sleep(") ||
+		strings.Contains(v, "pg_sleep") ||
+		strings.Contains(v, "waitfordelay") ||
+		strings.Contains(v, "benchmark(")
+}
+
+// embeddedRequestDataCheck looks for parameters that carry whole
+// cookies or blocks of headers. They usually belong to debug or
+// proxy endpoints that echo request data back, which can be handy
+// for session manipulation or request smuggling.
+func embeddedRequestDataCheck(k, v string) bool {
+	k = strings.ToLower(k)
+	v = strings.ToLower(v)
+
+	switch k {
+	case "cookie", "cookies", "set-cookie", "header", "headers":
+		return true
+	}
+
+	// name=value pairs with cookie attributes
+	if strings.Contains(v, "=") && strings.Contains(v, ";") {
+		if strings.Contains(v, "path=") ||
+			strings.Contains(v, "expires=") ||
+			strings.Contains(v, "samesite=") ||
+			strings.Contains(v, "httponly") {
+			return true
+		}
+	}
+
+	// Name: value header lines
+	return strings.Contains(v, "\n") && strings.Contains(v, ": ")
+}
+
+// altEncodingCheck looks for parameter values that are base32 or
+// hex encoded and have something suspicious inside them. These
+// encodings sometimes get used to sneak payloads past WAFs or to
+// exfiltrate data. The detail is param=encoding for each match.
+func altEncodingCheck(k, v string) (string, bool) {
+	enc, ok := decodeAltEncoding(v)
+	return k + "=" + enc, ok
+}
+
+// decodeAltEncoding tries to decode v as base32 or hex, returning
+// the name of the encoding if it decodes to something suspicious
+func decodeAltEncoding(v string) (string, bool) {
+	// Short values are too likely to happen to be
+	// valid hex or base32 by accident
+	if len(v) < 16 {
+		return "", false
+	}
+
+	if isBase32(v) {
+		b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(v, "="))
+		if err == nil && containsSuspiciousToken(string(b)) {
+			return "base32", true
+		}
+	}
+
+	if len(v)%2 == 0 {
+		b, err := hex.DecodeString(v)
+		if err == nil && containsSuspiciousToken(string(b)) {
+			return "hex", true
+		}
+	}
+
+	return "", false
+}
+
+// isBase32 returns true if v only contains characters from
+// the standard base32 alphabet; i.e. A-Z, 2-7 and = padding
+func isBase32(v string) bool {
+	for _, r := range v {
+		if (r < 'A' || r > 'Z') && (r < '2' || r > '7') && r != '=' {
+			return false
+		}
+	}
+	return true
+}
+
+// containsSuspiciousToken returns true if s contains
+// something that commonly turns up in attack payloads
+func containsSuspiciousToken(s string) bool {
+	tokens := []string{
+		"<script",
+		"javascript:",
+		"onerror=",
+		"../",
+		"..\\",
+		"/etc/passwd",
+		"<?php",
+		"{{",
+		"${",
+		"eval(",
+		"exec(",
+		"system(",
+		"union select",
+		"select ",
+		"sleep(",
+		"http://",
+		"https://",
+	}
+
+	s = strings.ToLower(s)
+	for _, t := range tokens {
+		if strings.Contains(s, t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// corsSurfaceCheck looks for parameters that set an origin or a
+// JSONP callback. Origins passed in parameters are often reflected
+// into Access-Control-Allow-Origin, so they're worth active testing.
+func corsSurfaceCheck(k, v string) bool {
+	k = strings.ToLower(k)
+
+	switch k {
+	case "origin", "cors", "callback", "cb", "jsonp", "jsoncallback", "jsonp_callback":
+		return true
+	}
+
+	return strings.HasSuffix(k, "_origin") || strings.HasSuffix(k, "origins")
+}
+
+// adminPanelCheck looks for the login and admin pages of well-known
+// CMSs and frameworks. The detail is the platform if we know which
+// one it is, or the matched path if we don't.
+func adminPanelCheck(u *url.URL) (string, bool) {
+	panels := []struct {
+		path     string
+		platform string
+	}{
+		{"/wp-admin", "WordPress"},
+		{"/wp-login.php", "WordPress"},
+		{"/administrator", "Joomla"},
+		{"/user/login", "Drupal"},
+		{"/umbraco", "Umbraco"},
+		{"/typo3", "TYPO3"},
+		{"/ghost", "Ghost"},
+		{"/phpmyadmin", "phpMyAdmin"},
+		{"/adminer.php", "Adminer"},
+		{"/manager/html", "Tomcat"},
+		{"/admin/login", ""},
+		{"/admin.php", ""},
+	}
+
+	// Add a trailing slash so that we only match on whole path segments
+	p := strings.ToLower(u.EscapedPath()) + "/"
+	for _, a := range panels {
+		if !strings.Contains(p, a.path+"/") {
+			continue
+		}
+
+		if a.platform == "" {
+			return a.path, true
+		}
+		return a.platform, true
+	}
+
+	return "", false
+}
+
+// infoDisclosureCheck looks for parameters that switch on
+// diagnostic output like stack traces, SQL queries or the
+// output of phpinfo()
+func infoDisclosureCheck(k, v string) bool {
+	k = strings.ReplaceAll(strings.ToLower(k), "-", "_")
+
+	switch k {
+	case "debug", "trace", "verbose", "showsql", "show_sql",
+		"stacktrace", "stack_trace", "errors", "show_errors",
+		"display_errors", "phpinfo", "xdebug", "profile":
+		return isEnablingValue(v)
+	}
+
+	return false
+}
+
+// isDisablingValue returns true if v looks like
+// it's switching something off
+func isDisablingValue(v string) bool {
+	switch strings.ToLower(v) {
+	case "0", "false", "no", "n", "off", "none", "disable", "disabled":
+		return true
+	}
+	return false
+}
+
+// isEnablingValue returns true if v looks like it's switching
+// something on. Empty values count because a bare ?debug is
+// often enough to turn a feature on.
+func isEnablingValue(v string) bool {
+	switch strings.ToLower(v) {
+	case "", "1", "true", "yes", "y", "on", "all", "full", "enable", "enabled":
+		return true
+	}
+	return false
+}
+
+// redirectControlCheck looks for parameters that control how a
+// redirect happens, like ?redirect_type=301 or ?status=302. With
+// a redirect target alongside them they make for manipulable
+// redirects, and they're handy for cache poisoning too.
+func redirectControlCheck(k, v string) bool {
+	k = strings.ToLower(k)
+
+	if strings.Contains(k, "redirect") {
+		for _, s := range []string{"type", "status", "code", "mode"} {
+			if strings.Contains(k, s) {
+				return true
+			}
+		}
+	}
+
+	// More generic names only count if the value is a redirect status
+	switch k {
+	case "status", "status_code", "statuscode", "http_status", "response_code", "code":
+		return len(v) == 3 && strings.HasPrefix(v, "30")
+	}
+
+	return false
+}
+
+// mimeControlCheck looks for parameters that let the client decide
+// the type or extension of a file. They're a good way around upload
+// filters, and can make content sniffing issues exploitable.
+func mimeControlCheck(k, v string) bool {
+	k = strings.ToLower(k)
+	k = strings.ReplaceAll(k, "_", "")
+	k = strings.ReplaceAll(k, "-", "")
+
+	switch k {
+	case "contenttype", "mimetype", "mime", "filename", "filetype", "ext", "extension":
+		return true
+	}
+
+	return false
+}
+
+// nestedSSRFCheck looks for parameter values that are themselves URLs
+// with a parameter pointing at an internal host, like:
+//
+//	?next=https://example.com/redirect?url=http://169.254.169.254/
+//
+// Chains like that get through filters that only look at the first
+// URL. It only goes one level deep.
+func nestedSSRFCheck(k, v string) bool {
+	return hasInternalURLParam(v)
+}
+
+// hasInternalURLParam returns true if v is a URL with a
+// parameter whose value is a URL for an internal host
+func hasInternalURLParam(v string) bool {
+	inner, err := url.Parse(v)
+	if err != nil || inner.Host == "" {
+		return false
+	}
+
+	for _, ivv := range inner.Query() {
+		for _, iv := range ivv {
+			target, err := url.Parse(iv)
+			if err != nil || target.Host == "" {
+				continue
+			}
+
+			if isInternalHost(target.Hostname()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isInternalHost returns true for hostnames and IP addresses
+// that point at loopback, private or link-local addresses, or
+// at cloud metadata services
+func isInternalHost(h string) bool {
+	h = normalizeHost(h)
+
+	if h == "localhost" || strings.HasSuffix(h, ".localhost") ||
+		h == "metadata" || strings.HasSuffix(h, ".internal") {
+		return true
+	}
+
+	// Strip any IPv6 zone before parsing
+	if i := strings.Index(h, "%"); i != -1 {
+		h = h[:i]
+	}
+
+	ip := net.ParseIP(h)
+	if ip == nil {
+		return false
+	}
+
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsUnspecified()
+}
+
+// uaParamCheck looks for parameters that let the client say who or
+// what it is, and for values carrying well-known bot or scanner
+// names. Either can point to functionality gated on the user agent.
+func uaParamCheck(k, v string) bool {
+	k = strings.ToLower(k)
+	k = strings.ReplaceAll(k, "_", "")
+	k = strings.ReplaceAll(k, "-", "")
+
+	switch k {
+	case "ua", "useragent", "agent", "bot", "crawler", "spider":
+		return true
+	}
+
+	v = strings.ToLower(v)
+	for _, sig := range []string{"googlebot", "bingbot", "sqlmap", "nikto", "nuclei", "masscan", "zgrab"} {
+		if strings.Contains(v, sig) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// emailInjectionCheck looks for parameters that suggest the endpoint
+// sends email, which makes it worth testing for header injection
+// and template injection
+func emailInjectionCheck(k, v string) bool {
+	k = strings.ReplaceAll(strings.ToLower(k), "-", "_")
+
+	switch k {
+	case "subject", "body", "cc", "bcc", "replyto", "reply_to", "template_id", "email_template", "mail_template":
+		return true
+
+	// to and from get used for all sorts of things
+	// (dates, currencies...) so only count them if
+	// they look like they've got an email address
+	case "to", "from":
+		return strings.Contains(v, "@")
+	}
+
+	return false
+}
+
+// emailInjectionPayloadCheck bumps the score of email parameters
+// whose values already contain line breaks or template syntax
+func emailInjectionPayloadCheck(k, v string) bool {
+	if !emailInjectionCheck(k, v) {
+		return false
+	}
+
+	return strings.ContainsAny(v, "\r\n") ||
+		strings.Contains(v, "{{") ||
+		strings.Contains(v, "${") ||
+		strings.Contains(v, "<%")
+}
+
+// serializedStateCheck looks for long, opaque values in parameters
+// that usually carry serialized state (e.g. ASP.NET's __VIEWSTATE or
+// signed session blobs), which make good deserialization targets.
+// Shannon entropy is used to tell opaque blobs from plain text. The
+// detail is param:entropy for each match.
+func serializedStateCheck(k, v string) (string, bool) {
+	switch strings.ToLower(k) {
+	case "state", "viewstate", "__viewstate", "__eventvalidation",
+		"session", "sessiondata", "data", "payload", "blob":
+	default:
+		return "", false
+	}
+
+	if len(v) < 20 {
+		return "", false
+	}
+
+	e := shannonEntropy(v)
+	return fmt.Sprintf("%s:%.2f", k, e), e >= 4.0
+}
+
+// shannonEntropy returns the Shannon entropy of
+// s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	e := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		e -= p * math.Log2(p)
+	}
+
+	return e
+}
+
+// restActionCheck looks for REST-style endpoints whose last path
+// segment is a state-changing or privileged action, like
+// /users/123/delete. They're prime CSRF and authz targets.
+func restActionCheck(u *url.URL) (string, bool) {
+	actions := []string{
+		"delete",
+		"remove",
+		"destroy",
+		"edit",
+		"update",
+		"export",
+		"import",
+		"reset-password",
+		"change-password",
+		"impersonate",
+		"sudo",
+		"grant",
+		"promote",
+		"approve",
+	}
+
+	seg := lastPathSegment(u)
+
+	// Ignore any extension, e.g. /delete.json
+	if i := strings.Index(seg, "."); i != -1 {
+		seg = seg[:i]
+	}
+	seg = strings.ReplaceAll(seg, "_", "-")
+
+	for _, a := range actions {
+		if seg == a {
+			return a, true
+		}
+	}
+
+	return "", false
+}
+
+// jsonQueryParamCheck looks for parameter values that are JSON
+// documents with query-like keys in them, like MongoDB operators
+// ({"$where": ...}) or GraphQL-ish filters. Parsing the JSON
+// rather than just looking for substrings keeps the noise down.
+func jsonQueryParamCheck(k, v string) bool {
+	v = strings.TrimSpace(v)
+	if !strings.HasPrefix(v, "{") && !strings.HasPrefix(v, "[") {
+		return false
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(v), &doc); err != nil {
+		return false
+	}
+
+	return hasQueryKey(doc)
+}
+
+// hasQueryKey walks a decoded JSON document looking for
+// object keys that suggest it's a query of some kind
+func hasQueryKey(doc interface{}) bool {
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		for k, v := range d {
+			// $-prefixed keys are MongoDB operators
+			// like $where, $regex, $ne and $gt
+			if strings.HasPrefix(k, "$") {
+				return true
+			}
+
+			switch strings.ToLower(k) {
+			case "filter", "query", "aggregate", "pipeline", "where":
+				return true
+			}
+
+			if hasQueryKey(v) {
+				return true
+			}
+		}
+
+	case []interface{}:
+		for _, v := range d {
+			if hasQueryKey(v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// selfValues holds the IDs etc that belong to the user the URLs
+// were collected as. Parameters set to one of them are skipped by
+// the horizontal-authz check.
+var selfValues = make(map[string]bool)
+
+// horizontalAuthzCheck looks for parameters that scope a request to
+// a user, account or tenant. Changing them is the classic IDOR test.
+func horizontalAuthzCheck(k, v string) bool {
+	if selfValues[v] {
+		return false
+	}
+
+	k = strings.ToLower(k)
+	k = strings.ReplaceAll(k, "_", "")
+	k = strings.ReplaceAll(k, "-", "")
+
+	// Allow for things like user and userid, account and accountid
+	k = strings.TrimSuffix(k, "id")
+
+	switch k {
+	case "user", "account", "acct", "customer", "profile", "member",
+		"org", "organization", "organisation", "tenant", "owner":
+		return true
+	}
+
+	return false
+}
+
+// securityDowngradeCheck looks for parameters that explicitly turn
+// off a security feature: either a negative name switched on (e.g.
+// ?insecure=1, ?skip_auth=true) or a feature switched off (e.g.
+// ?verify=false, ?ssl=0)
+func securityDowngradeCheck(k, v string) bool {
+	k = strings.ToLower(k)
+	k = strings.ReplaceAll(k, "_", "")
+	k = strings.ReplaceAll(k, "-", "")
+
+	switch k {
+	case "insecure", "nossl", "notls", "noauth", "skipauth", "bypassauth",
+		"disableauth", "nocsrf", "skipcsrf", "disablecsrf", "noverify",
+		"skipverify", "insecureskipverify", "disablesecurity", "nosecurity":
+		return isEnablingValue(v)
+
+	case "ssl", "tls", "https", "secure", "verify", "verifyssl", "sslverify",
+		"auth", "csrf", "validate", "checkcertificate", "security":
+		return isDisablingValue(v)
+	}
+
+	return false
+}
+
+var (
+	coordRe    = regexp.MustCompile(`^-?[0-9]{1,3}(\.[0-9]+)?$`)
+	coordsRe   = regexp.MustCompile(`^-?[0-9]{1,3}(\.[0-9]+)?, ?-?[0-9]{1,3}(\.[0-9]+)?$`)
+	postcodeRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{2,9}$`)
+)
+
+// geoEnumCheck looks for coordinates and postcodes in parameters.
+// Endpoints that take them can often be walked to enumerate stores,
+// users or whatever else is near a location.
+func geoEnumCheck(k, v string) bool {
+	k = strings.ReplaceAll(strings.ToLower(k), "-", "_")
+
+	switch k {
+	case "lat", "lng", "lon", "long", "latitude", "longitude":
+		return coordRe.MatchString(v)
+	case "geo", "latlng", "latlon", "ll", "coords", "location":
+		return coordsRe.MatchString(v)
+	case "zip", "zipcode", "zip_code", "postcode", "post_code", "postal_code", "postalcode":
+		return postcodeRe.MatchString(v)
+	}
+
+	return false
+}
+
+// wafTarget is the bits of a URL that the WAF bypass signatures
+// look at: the raw path and query string, the same again decoded,
+// and the parsed query string
+type wafTarget struct {
+	raw     string
+	decoded string
+	query   url.Values
+}
+
+var (
+	mixedCaseRe     = regexp.MustCompile(`(?i)\b(select|union|insert|update|delete|from|where|script|alert|onerror|onload|javascript)\b`)
+	doubleEncodedRe = regexp.MustCompile(`(?i)%25[0-9a-f]{2}`)
+	iisUnicodeRe    = regexp.MustCompile(`(?i)%u[0-9a-f]{4}`)
+)
+
+// wafBypassSignatures are the evasion tricks that the waf-bypass
+// check knows about. To add a new one, just add it to the list.
+var wafBypassSignatures = []struct {
+	name  string
+	match func(t wafTarget) bool
+}{
+	// MySQL versioned comments, e.g. /*!50000union*/
+	{"versioned-comment", func(t wafTarget) bool {
+		return strings.Contains(t.decoded, "/*!")
+	}},
+
+	// Empty comments in place of spaces, e.g. union/**/select
+	{"inline-comment", func(t wafTarget) bool {
+		return strings.Contains(t.decoded, "/**/")
+	}},
+
+	// Keywords in mIxEd case, e.g. UnIoN SeLeCt. CamelCase spellings
+	// like JavaScript and onLoad only change case twice, so it takes
+	// at least three changes after the first letter to count.
+	{"mixed-case-keyword", func(t wafTarget) bool {
+		for _, m := range mixedCaseRe.FindAllString(t.decoded, -1) {
+			if caseChanges(m[1:]) >= 3 {
+				return true
+			}
+		}
+		return false
+	}},
+
+	// %00, or escaped forms like \x00 and \u0000
+	{"encoded-null", func(t wafTarget) bool {
+		return strings.Contains(t.decoded, "\x00") ||
+			strings.Contains(t.decoded, `\x00`) ||
+			strings.Contains(t.decoded, `\u0000`)
+	}},
+
+	// %2527 and friends decode to %27 the first time round
+	{"double-encoding", func(t wafTarget) bool {
+		return doubleEncodedRe.MatchString(t.raw)
+	}},
+
+	// IIS-style %u0027, or fullwidth lookalikes like ＜ and ＇
+	{"unicode-lookalike", func(t wafTarget) bool {
+		if iisUnicodeRe.MatchString(t.raw) {
+			return true
+		}
+		for _, r := range t.decoded {
+			if r >= 0xFF01 && r <= 0xFF5E {
+				return true
+			}
+		}
+		return false
+	}},
+
+	// A comment opened in one parameter and closed in another,
+	// e.g. ?a=1/*&b=*/union select, splits a payload so that no
+	// single parameter looks like an attack
+	{"param-fragmentation", func(t wafTarget) bool {
+		opened, closed := false, false
+		for _, vv := range t.query {
+			for _, v := range vv {
+				o := strings.Contains(v, "/*")
+				c := strings.Contains(v, "*/")
+				if o && c {
+					continue
+				}
+				opened = opened || o
+				closed = closed || c
+			}
+		}
+		return opened && closed
+	}},
+}
+
+// caseChanges returns the number of times s switches between
+// upper and lower case letters
+func caseChanges(s string) int {
+	n := 0
+	upper := false
+	for i, r := range s {
+		u := r >= 'A' && r <= 'Z'
+		if i > 0 && u != upper {
+			n++
+		}
+		upper = u
+	}
+	return n
+}
+
+// wafBypassCheck looks for URLs that seem to have been crafted to
+// get past a WAF. In logs they're evidence of an attack, and they
+// make for good testing inspiration too. The detail is the names
+// of the signatures that matched.
+func wafBypassCheck(u *url.URL) (string, bool) {
+	raw := u.EscapedPath()
+	if u.RawQuery != "" {
+		raw += "?" + u.RawQuery
+	}
+
+	decoded, err := url.QueryUnescape(raw)
+	if err != nil {
+		decoded = raw
+	}
+
+	t := wafTarget{raw, decoded, u.Query()}
+
+	found := make([]string, 0)
+	for _, sig := range wafBypassSignatures {
+		if sig.match(t) {
+			found = append(found, sig.name)
+		}
+	}
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// queryCompositeCheck scores the query string as a whole rather than
+// one parameter at a time. It's a catch-all for busy, suspicious
+// looking query strings: lots of special characters, lots of percent
+// encoding, or just very long. The detail shows the metrics.
+func queryCompositeCheck(u *url.URL) (string, bool) {
+	raw := u.RawQuery
+	if raw == "" {
+		return "", false
+	}
+
+	decoded, err := url.QueryUnescape(raw)
+	if err != nil {
+		decoded = raw
+	}
+
+	special := 0
+	for _, r := range decoded {
+		if strings.ContainsRune("'\"<>(){}[];|$`!*\\", r) {
+			special++
+		}
+	}
+
+	encoded := float64(strings.Count(raw, "%")*3) / float64(len(raw))
+	if encoded > 1 {
+		encoded = 1
+	}
+
+	detail := fmt.Sprintf("len=%d,special=%d,encoded=%.2f", len(raw), special, encoded)
+
+	return detail, special >= 10 ||
+		(encoded >= 0.4 && len(raw) >= 60) ||
+		len(raw) >= 1024
+}
+
+// matrixParamsCheck notes URLs that use matrix parameters. They're
+// unusual and framework-specific, so worth a look in their own right.
+// The detail is the names of the parameters.
+func matrixParamsCheck(u *url.URL) (string, bool) {
+	keys := make([]string, 0)
+	for k := range matrixParams(u) {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return strings.Join(keys, ","), len(keys) > 0
+}
+
+// sqliRe matches the quote, comment and keyword patterns of
+// typical SQL injection probes and payloads
+var sqliRe = regexp.MustCompile(`(?i)` +
+	// a number followed by a quote, e.g. 1'
+	`^-?[0-9]+\s*['"]|` +
+	// a quote followed by more SQL, e.g. ' or, ') --
+	`['"]\s*(or|and|union|order|group|having|select|;|--(\s|\+|$)|\)|\|\|)|` +
+	// union select, including with comments for spaces
+	`\bunion(\s|\+|/\*.*?\*/)+(all(\s|\+)+)?select\b|` +
+	// select ... from, but only when what's selected looks like a
+	// column list (*, @@version, a or a,b(c)) rather than any words
+	`\bselect(\s|\+|/\*.*?\*/)+(\*|@@\w+|[\w.]+(\(.*?\))?((\s|\+)*,(\s|\+)*[\w.]+(\(.*?\))?)*)(\s|\+|/\*.*?\*/)+from\b|` +
+	// tautologies, e.g. or 1=1
+	`\b(or|and)\s+[0-9]+\s*=\s*[0-9]+|` +
+	// a trailing comment after a quote and any closing brackets,
+	// e.g. 1'-- or 1')/*. -- only counts as a comment when it's
+	// followed by whitespace or the end, so slugs like v2--beta
+	// don't match
+	`['"]\s*\)*\s*(--(\s|\+|$)|/\*)`)
+
+// sqliInPathCheck runs the SQL injection heuristics against each
+// path segment. RESTful and rewritten URLs often put injectable
+// values in the path (e.g. /product/1'/details) rather than the
+// query string. The detail is the first matching segment.
+func sqliInPathCheck(u *url.URL) (string, bool) {
+	for _, seg := range strings.Split(u.EscapedPath(), "/") {
+		if seg == "" {
+			continue
+		}
+
+		if d, err := url.PathUnescape(seg); err == nil {
+			seg = d
+		}
+
+		if sqliRe.MatchString(seg) {
+			return seg, true
+		}
+	}
+
+	// Payloads like union/**/select span more than one segment
+	p := u.EscapedPath()
+	if d, err := url.PathUnescape(p); err == nil {
+		p = d
+	}
+
+	if sqliRe.MatchString(p) {
+		return p, true
+	}
+
+	return "", false
+}
+
+// internalAPIRefCheck looks for routing-style parameters (proxy,
+// forward, url etc) whose value is an internal-looking API path.
+// It's a common way for gateways to end up letting you reach
+// internal services.
+func internalAPIRefCheck(k, v string) bool {
+	k = strings.ToLower(k)
+
+	routing := false
+	for _, r := range []string{"url", "uri", "path", "proxy", "forward", "target", "dest", "endpoint", "route", "service", "upstream", "backend"} {
+		if strings.Contains(k, r) {
+			routing = true
+			break
+		}
+	}
+	if !routing {
+		return false
+	}
+
+	// The value might be a path or a full URL
+	p := v
+	if t, err := url.Parse(v); err == nil && t.Host != "" {
+		p = t.Path
+	}
+	p = strings.ToLower(p) + "/"
+
+	for _, i := range []string{"/internal/", "/_internal/", "/api/internal/", "/admin/api/", "/private/", "/_api/", "/management/", "/actuator/"} {
+		if strings.Contains(p, i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// windowsPathRe matches the start of an absolute Windows path
+var windowsPathRe = regexp.MustCompile(`^[a-zA-Z]:[\\/]`)
+
+// absolutePathValueCheck looks for parameter values that are absolute
+// filesystem paths. They point at file access even when there's no
+// ../ to give it away. The detail is param=path for each match.
+func absolutePathValueCheck(k, v string) (string, bool) {
+	p, ok := absolutePath(v)
+	return k + "=" + p, ok
+}
+
+// absolutePath returns v without any file:// prefix,
+// if it looks like an absolute filesystem path
+func absolutePath(v string) (string, bool) {
+	p := v
+	if strings.HasPrefix(strings.ToLower(p), "file://") {
+		p = p[len("file://"):]
+	}
+
+	// Windows paths are case-insensitive, Unix ones aren't: matching
+	// them case-sensitively keeps out app routes like /Home/Index
+	if windowsPathRe.MatchString(p) || strings.HasPrefix(strings.ToLower(p), "/windows/") {
+		return p, true
+	}
+
+	prefixes := []string{
+		"/etc/",
+		"/var/www/",
+		"/home/",
+		"/root/",
+		"/usr/local/",
+		"/opt/",
+		"/tmp/",
+		"/proc/",
+		"/srv/",
+	}
+
+	for _, pre := range prefixes {
+		if strings.HasPrefix(p, pre) {
+			return p, true
+		}
+	}
+
+	return "", false
+}
+
+// presignedURLCheck looks for the clusters of parameters that make
+// up a cloud storage pre-signed URL. They might be leaked, still be
+// replayable, or give away the structure of a bucket. The detail is
+// the cloud provider.
+func presignedURLCheck(u *url.URL) (string, bool) {
+	has := make(map[string]bool)
+	for k := range u.Query() {
+		has[strings.ToLower(k)] = true
+	}
+
+	switch {
+	// AWS Signature Version 4
+	case has["x-amz-signature"] && (has["x-amz-credential"] || has["x-amz-algorithm"]):
+		return "aws", true
+
+	// AWS Signature Version 2
+	case has["awsaccesskeyid"] && has["signature"] && has["expires"]:
+		return "aws", true
+
+	// Google Cloud Storage, V4 and V2
+	case has["x-goog-signature"] && (has["x-goog-credential"] || has["x-goog-algorithm"]):
+		return "gcs", true
+	case has["googleaccessid"] && has["signature"] && has["expires"]:
+		return "gcs", true
+
+	// Azure shared access signatures
+	case has["sig"] && has["se"] && (has["sv"] || has["sp"]):
+		return "azure", true
+	}
+
+	return "", false
+}
+
+// polyglotSignatures are fragments of well-known polyglot payloads,
+// lowercased. Most of them come from the XSS polyglots that do the
+// rounds, which break out of several contexts at once.
+var polyglotSignatures = []string{
+	"javascript:/*-/*`/*",
+	"/*-/*`/*\\`/*'/*\"/**/",
+	"*/--></title></style></textarea></script>",
+	"--></style></script>",
+	"'\"--></style></script>",
+	"\"'><svg/onload=",
+	"'\"><img src=x onerror=",
+	"{{7*7}}${7*7}<%= 7*7 %>",
+}
+
+// injectionContexts are the kinds of context that a payload can try to
+// break out of, along with the markers that suggest it's trying to
+var injectionContexts = []struct {
+	name    string
+	markers []string
+}{
+	{"quote", []string{"'", "\"", "`"}},
+	{"tag", []string{"<", ">"}},
+	{"paren", []string{"(", ")"}},
+	{"template", []string{"{{", "${", "<%", "#{"}},
+	{"comment", []string{"/*", "*/", "-->", "<!--"}},
+}
+
+// minPolyglotContexts is how many different injection contexts
+// a value has to have markers for to count as a polyglot
+const minPolyglotContexts = 4
+
+// polyglotPayloadCheck looks for parameter values that are built to
+// work in several injection contexts at once, either because they
+// contain a known polyglot or because they mix the markers for lots
+// of different contexts. Payloads like that are almost never there
+// by accident. The detail is param=signature or param=contexts:N.
+func polyglotPayloadCheck(k, v string) (string, bool) {
+	d, ok := polyglotPayload(v)
+	return k + "=" + d, ok
+}
+
+// polyglotPayload returns a short description of why
+// v looks like a polyglot payload, if it does
+func polyglotPayload(v string) (string, bool) {
+	lv := strings.ToLower(v)
+
+	for _, sig := range polyglotSignatures {
+		if strings.Contains(lv, sig) {
+			return "signature", true
+		}
+	}
+
+	n := 0
+	for _, ctx := range injectionContexts {
+		for _, m := range ctx.markers {
+			if strings.Contains(lv, m) {
+				n++
+				break
+			}
+		}
+	}
+
+	if n >= minPolyglotContexts {
+		return fmt.Sprintf("contexts:%d", n), true
+	}
+
+	return "", false
+}
+
+// crlfPathRe matches encoded line breaks in a raw path, including
+// double-encoded ones like %250d%250a and escaped ones like \r\n.
+// An escaped r or n has to end a segment or be followed by another
+// backslash so that Windows-style paths like ..\node_modules don't match
+var crlfPathRe = regexp.MustCompile(`(?i)%(25)?0[da]|%5c[rn](%5c|/|$)`)
+
+// smugglingIndicatorCheck looks for the URL-level traces of request
+// smuggling and proxy confusion: framing headers passed as parameters,
+// more than one Host-like parameter, and line breaks encoded into the
+// path for an upstream parser to trip over. The detail is the names of
+// the indicators that were found.
+func smugglingIndicatorCheck(u *url.URL) (string, bool) {
+	found := make([]string, 0)
+
+	te, cl, hosts := false, false, 0
+	for k, vv := range params(u) {
+		k = strings.ReplaceAll(strings.ToLower(k), "_", "-")
+
+		switch k {
+		case "transfer-encoding":
+			te = true
+		case "content-length":
+			cl = true
+		case "host", "x-forwarded-host", "x-host", "x-original-host", "forwarded-host":
+			hosts += len(vv)
+		}
+	}
+
+	if te {
+		found = append(found, "transfer-encoding")
+	}
+	if cl {
+		found = append(found, "content-length")
+	}
+	if hosts > 1 {
+		found = append(found, "multiple-host")
+	}
+	if crlfPathRe.MatchString(u.EscapedPath()) {
+		found = append(found, "crlf-in-path")
+	}
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// keySmugglingCheck looks for query strings with more than one way of
+// writing the same parameter name, like ?id=1&ID=2 or ?id=1&%69d=2.
+// Backends that ignore case or decode names see them as the same
+// parameter while filters in front of them might not, which makes
+// for parameter smuggling. The detail is the colliding raw names,
+// separated by |, for each name that collides.
+func keySmugglingCheck(u *url.URL) (string, bool) {
+	forms := make(map[string][]string)
+
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		k := pair
+		if i := strings.Index(pair, "="); i != -1 {
+			k = pair[:i]
+		}
+		if k == "" {
+			continue
+		}
+
+		norm := k
+		if dk, err := url.QueryUnescape(k); err == nil {
+			norm = dk
+		}
+		norm = strings.ToLower(norm)
+
+		seen := false
+		for _, f := range forms[norm] {
+			if f == k {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			forms[norm] = append(forms[norm], k)
+		}
+	}
+
+	found := make([]string, 0)
+	for _, ff := range forms {
+		if len(ff) > 1 {
+			found = append(found, strings.Join(ff, "|"))
+		}
+	}
+	sort.Strings(found)
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+var (
+	cardRe   = regexp.MustCompile(`^[0-9]{4}([ -]?[0-9]{4}){3}$|^[0-9]{4}[ -]?[0-9]{6}[ -]?[0-9]{5}$`)
+	ssnRe    = regexp.MustCompile(`^[0-9]{3}-[0-9]{2}-[0-9]{4}$`)
+	phoneRe  = regexp.MustCompile(`^(\+[0-9]{1,3}[ .-]?)?(\([0-9]{2,4}\)[ .-]?)?[0-9]{2,4}([ .-][0-9]{2,4}){1,4}$|^\+[0-9]{8,15}$`)
+	bearerRe = regexp.MustCompile(`(?i)^bearer\s+[a-z0-9._~+/=-]{10,}$`)
+)
+
+// sensitiveValueFormatCheck looks for parameter values that are
+// formatted like sensitive data, whatever the parameter is called.
+// The detail is param=type for each match; the values themselves
+// are left out so that they don't end up spread around even more.
+func sensitiveValueFormatCheck(k, v string) (string, bool) {
+	t, ok := sensitiveValueType(strings.TrimSpace(v))
+	return k + "=" + t, ok
+}
+
+// sensitiveValueType returns the kind of sensitive
+// data v looks like, if it looks like any
+func sensitiveValueType(v string) (string, bool) {
+	switch {
+	case strings.Contains(v, "-----BEGIN") && strings.Contains(v, "PRIVATE KEY"):
+		return "private-key", true
+	case bearerRe.MatchString(v):
+		return "bearer-token", true
+	case isCardNumber(v):
+		return "card-number", true
+	case ssnRe.MatchString(v):
+		return "ssn", true
+	case isPhoneNumber(v):
+		return "phone-number", true
+	}
+
+	return "", false
+}
+
+// isCardNumber returns true if v is a 15 or 16 digit number, with
+// or without separators, that starts with the prefix of a major card
+// network and passes the Luhn check. The prefix matters: lots of
+// numbers pass Luhn by chance (about 1 in 10 of them), like the
+// timestamps used as cache-busters.
+func isCardNumber(v string) bool {
+	if !cardRe.MatchString(v) {
+		return false
+	}
+
+	d := strings.NewReplacer(" ", "", "-", "").Replace(v)
+	if looksLikeEpoch(d) {
+		return false
+	}
+
+	return hasCardPrefix(d) && luhnValid(d)
+}
+
+// hasCardPrefix returns true if the digits in d start with the issuer
+// prefix of a major card network and have the right length for it
+func hasCardPrefix(d string) bool {
+	prefix := func(lo, hi, n int) bool {
+		p := 0
+		for _, r := range d[:n] {
+			p = p*10 + int(r-'0')
+		}
+		return p >= lo && p <= hi
+	}
+
+	if len(d) == 15 {
+		// American Express
+		return prefix(34, 34, 2) || prefix(37, 37, 2)
+	}
+
+	return d[0] == '4' || // Visa
+		prefix(51, 55, 2) || prefix(2221, 2720, 4) || // Mastercard
+		prefix(6011, 6011, 4) || prefix(644, 649, 3) || prefix(65, 65, 2) || // Discover
+		prefix(3528, 3589, 4) || // JCB
+		prefix(62, 62, 2) // UnionPay
+}
+
+// looksLikeEpoch returns true if d is all digits and could be a Unix
+// timestamp in seconds, milliseconds, microseconds or nanoseconds
+// somewhere between 2001 and 2038. The upper bound stays below 2221,
+// the lowest card prefix, so real card numbers are never mistaken for one
+func looksLikeEpoch(d string) bool {
+	switch len(d) {
+	case 10, 13, 16, 19:
+	default:
+		return false
+	}
+
+	for _, r := range d {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	secs := 0
+	for _, r := range d[:10] {
+		secs = secs*10 + int(r-'0')
+	}
+
+	return secs >= 1000000000 && secs <= 2147483647
+}
+
+// isPhoneNumber returns true if v looks like a phone number with
+// 10 to 15 digits. Dots are a common separator, but so are they in
+// IP addresses and version numbers, so dotted values with four or
+// more groups of digits don't count.
+func isPhoneNumber(v string) bool {
+	n := countDigits(v)
+	if n < 10 || n > 15 || !phoneRe.MatchString(v) {
+		return false
+	}
+
+	groups := strings.FieldsFunc(v, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	return !(strings.Contains(v, ".") && len(groups) >= 4)
+}
+
+// luhnValid returns true if the digits in v pass the Luhn
+// check used by payment card numbers. Anything that isn't
+// a digit (e.g. spaces and dashes) is ignored.
+func luhnValid(v string) bool {
+	sum, n := 0, 0
+	for i := len(v) - 1; i >= 0; i-- {
+		if v[i] < '0' || v[i] > '9' {
+			continue
+		}
+
+		d := int(v[i] - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+
+	return n > 0 && sum%10 == 0
+}
+
+// countDigits returns the number of ASCII digits in v
+func countDigits(v string) int {
+	n := 0
+	for _, r := range v {
+		if r >= '0' && r <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+var (
+	// Arithmetic wrapped in the expression syntax of a template
+	// engine or expression language, e.g. {{7*7}}, ${7*7}, #{7*7},
+	// <%= 7*7 %>, *{7*7} or @(7*7)
+	templateExprRe = regexp.MustCompile(`(\{\{|\$\{|#\{|\*\{|<%=?|@\()\s*[0-9]+\s*[-+*/%]\s*['"]?[0-9]+['"]?\s*(\}\}|\}|%>|\))`)
+
+	// Bare multiplication of small numbers, e.g. 7*7. Bigger ones
+	// are dimensions like 1920*1080, and other operators are too
+	// likely to turn up in dates, ranges and the like.
+	bareExprRe = regexp.MustCompile(`^\s*[0-9]{1,2}\s*\*\s*[0-9]{1,2}\s*$`)
+)
+
+// expressionEvalCheck looks for the arithmetic probes used to test for
+// server-side template injection, like {{7*7}}, along with the tell-tale
+// result of {{7*'7'}} in Jinja2 and Twig. Either means somebody thought
+// the parameter was worth testing for SSTI, or that it's been tested.
+func expressionEvalCheck(k, v string) bool {
+	if templateExprRe.MatchString(v) || bareExprRe.MatchString(v) {
+		return true
+	}
+
+	return v == "7777777"
+}
+
+// frameworkTarget is the bits of a URL that the framework exploit
+// signatures look at: the lowercased path, raw and decoded, the
+// decoded query string and the parsed parameters
+type frameworkTarget struct {
+	rawPath string
+	path    string
+	query   string
+	params  url.Values
+}
+
+// frameworkExploits are the signatures known to the framework-exploit
+// check. Each has the framework it belongs to and the class of exploit.
+var frameworkExploits = []struct {
+	framework string
+	class     string
+	match     func(t frameworkTarget) bool
+}{
+	// OGNL expressions sent to Struts actions, e.g. S2-045 and S2-057
+	{"struts", "ognl", func(t frameworkTarget) bool {
+		if !strings.HasSuffix(t.path, ".action") && !strings.HasSuffix(t.path, ".do") && !strings.Contains(t.path, ".action/") {
+			return false
+		}
+		return strings.Contains(t.path, "%{") || strings.Contains(t.path, "${") ||
+			strings.Contains(t.query, "%{") || strings.Contains(t.query, "${")
+	}},
+
+	// redirect: and action: prefixed parameters (S2-016)
+	{"struts", "prefix-injection", func(t frameworkTarget) bool {
+		for k := range t.params {
+			k = strings.ToLower(k)
+			if strings.HasPrefix(k, "redirect:") || strings.HasPrefix(k, "redirectaction:") || strings.HasPrefix(k, "action:") {
+				return true
+			}
+		}
+		return false
+	}},
+
+	// Spring Cloud Gateway route injection (CVE-2022-22947)
+	{"spring", "gateway-spel", func(t frameworkTarget) bool {
+		return strings.Contains(t.path, "/actuator/gateway")
+	}},
+
+	// Spring Cloud Function routing expressions (CVE-2022-22963)
+	{"spring", "function-spel", func(t frameworkTarget) bool {
+		return strings.Contains(t.path, "/functionrouter") ||
+			strings.Contains(t.query, "spring.cloud.function.routing-expression")
+	}},
+
+	// Class loader manipulation, a.k.a. Spring4Shell (CVE-2022-22965)
+	{"spring", "class-loader", func(t frameworkTarget) bool {
+		for k := range t.params {
+			if strings.HasPrefix(strings.ToLower(k), "class.module.classloader") {
+				return true
+			}
+		}
+		return false
+	}},
+
+	// Apache httpd path traversal with encoded dots (CVE-2021-41773
+	// and CVE-2021-42013)
+	{"apache", "path-traversal", func(t frameworkTarget) bool {
+		return strings.Contains(t.rawPath, "/%2e") ||
+			strings.Contains(t.rawPath, ".%2e/") ||
+			strings.Contains(t.rawPath, "%%32%65")
+	}},
+
+	// JNDI lookups for Log4Shell (CVE-2021-44228)
+	{"log4j", "jndi", func(t frameworkTarget) bool {
+		return strings.Contains(t.path, "${jndi:") || strings.Contains(t.query, "${jndi:")
+	}},
+
+	// ThinkPHP controller invocation (CVE-2018-20062)
+	{"thinkphp", "invokefunction", func(t frameworkTarget) bool {
+		return strings.Contains(t.query, "invokefunction") && strings.Contains(t.query, "think")
+	}},
+}
+
+// frameworkExploitCheck looks for paths and parameters that go with
+// well-known framework RCE and traversal bugs. Generic checks pick
+// some of these up as just a bit interesting, but they're worth a lot
+// more than that. The detail is framework:class for each match.
+func frameworkExploitCheck(u *url.URL) (string, bool) {
+	rawPath := strings.ToLower(u.EscapedPath())
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		path = rawPath
+	}
+
+	query, err := url.QueryUnescape(strings.ToLower(u.RawQuery))
+	if err != nil {
+		query = strings.ToLower(u.RawQuery)
+	}
+
+	t := frameworkTarget{rawPath, path, query, params(u)}
+
+	found := make([]string, 0)
+	for _, e := range frameworkExploits {
+		if e.match(t) {
+			found = append(found, e.framework+":"+e.class)
+		}
+	}
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// ssrfDNSServices are domains whose subdomains resolve to whatever IP
+// is in their name (e.g. 127.0.0.1.nip.io), or that log the lookups
+// made against them for out-of-band testing
+var ssrfDNSServices = []string{
+	"nip.io",
+	"sslip.io",
+	"xip.io",
+	"localtest.me",
+	"lvh.me",
+	"1u.ms",
+	"burpcollaborator.net",
+	"oastify.com",
+	"oast.fun",
+	"oast.live",
+	"oast.me",
+	"oast.online",
+	"oast.pro",
+	"oast.site",
+	"interact.sh",
+	"interactsh.com",
+}
+
+// ssrfDNSServiceCheck looks for hosts on DNS services used for SSRF
+// testing, either as the URL's own host or as the host of a URL in a
+// parameter. They're used to get around filters that only look at
+// the hostname, and to confirm blind SSRF. The detail is the names
+// of the services.
+func ssrfDNSServiceCheck(u *url.URL) (string, bool) {
+	hosts := []string{u.Hostname()}
+
+	for _, vv := range params(u) {
+		for _, v := range vv {
+			if t, err := url.Parse(v); err == nil && t.Host != "" {
+				hosts = append(hosts, t.Hostname())
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	found := make([]string, 0)
+	for _, h := range hosts {
+		if svc, ok := ssrfDNSService(h); ok && !seen[svc] {
+			seen[svc] = true
+			found = append(found, svc)
+		}
+	}
+	sort.Strings(found)
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// ssrfDNSService returns the SSRF testing
+// service that host is on, if it's on one
+func ssrfDNSService(host string) (string, bool) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	for _, svc := range ssrfDNSServices {
+		if host == svc || strings.HasSuffix(host, "."+svc) {
+			return svc, true
+		}
+	}
+
+	return "", false
+}
+
+// featureFlagCheck looks for parameters named like feature flags or
+// A/B test switches. They can turn on hidden or unfinished features
+// that haven't had the same scrutiny as the rest of the app.
+func featureFlagCheck(k, v string) bool {
+	k = strings.ReplaceAll(strings.ToLower(k), "-", "_")
+
+	for _, p := range []string{"ff_", "feature_", "features_", "enable_", "flag_", "flags_", "experiment", "ab_test", "abtest"} {
+		if strings.HasPrefix(k, p) {
+			return true
+		}
+	}
+
+	switch k {
+	case "ff", "feature", "features", "flag", "flags", "variant", "variation", "beta", "preview", "canary":
+		return true
+	}
+
+	return false
+}
+
+// containerIndicators are paths and hostnames that only turn up when
+// something is poking at the internals of a container or cluster
+var containerIndicators = []string{
+	"docker.sock",
+	"/.dockerenv",
+	"/proc/self/cgroup",
+	"/proc/1/cgroup",
+	"kubernetes.default.svc",
+	"kubernetes.default",
+	"/run/secrets/kubernetes.io",
+	"/serviceaccount/token",
+	"/serviceaccount/ca.crt",
+	"/var/lib/kubelet",
+	"/etc/kubernetes",
+	".kube/config",
+}
+
+// containerMetadataCheck looks for references to Docker and Kubernetes
+// internals in the path or in parameter values. Reading the Docker
+// socket or a service account token is usually the way out of a
+// container, and the API server is a juicy SSRF target. The detail
+// is the indicators that were found.
+func containerMetadataCheck(u *url.URL) (string, bool) {
+	p := u.EscapedPath()
+	if d, err := url.PathUnescape(p); err == nil {
+		p = d
+	}
+
+	targets := []string{strings.ToLower(p)}
+	for _, vv := range params(u) {
+		for _, v := range vv {
+			targets = append(targets, strings.ToLower(v))
+		}
+	}
+
+	found := make([]string, 0)
+	for _, i := range containerIndicators {
+		// Don't report kubernetes.default when we've
+		// already got kubernetes.default.svc
+		if len(found) > 0 && strings.Contains(found[len(found)-1], i) {
+			continue
+		}
+
+		for _, t := range targets {
+			if strings.Contains(t, i) {
+				found = append(found, i)
+				break
+			}
+		}
+	}
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// ldapInjectionCheck looks for LDAP filter syntax in parameter values,
+// like *)(uid=*) or |(cn=*), and for parameters named after LDAP
+// attributes. Either suggests the value ends up in a search filter
+// against a directory, which is often what sits behind logins.
+func ldapInjectionCheck(k, v string) bool {
+	k = strings.ReplaceAll(strings.ToLower(k), "-", "_")
+
+	switch k {
+	case "uid", "cn", "dn", "ou", "ldap", "ldap_filter", "ldapfilter", "ldap_query",
+		"base_dn", "basedn", "search_filter", "searchfilter", "samaccountname":
+		return true
+	}
+
+	v = strings.ToLower(v)
+	for _, m := range []string{")(", "|(", "&(", "!(", `\28`, `\29`, `\2a`, "(uid=", "(cn=", "(objectclass="} {
+		if strings.Contains(v, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// controlCharCheck looks for control characters (e.g. backspace, bell
+// or escape) anywhere in a URL, whether they were in the input raw or
+// percent-encoded (parseURL encodes raw ones so they can be parsed).
+// They've got no business being there, so they're usually an attempt
+// to confuse a parser or a log viewer. Tabs and line breaks are left
+// to other checks. The detail is the characters found, in hex.
+func controlCharCheck(u *url.URL) (string, bool) {
+	p := u.EscapedPath()
+	if d, err := url.PathUnescape(p); err == nil {
+		p = d
+	}
+
+	parts := []string{p, u.Fragment}
+	for k, vv := range params(u) {
+		parts = append(parts, k)
+		parts = append(parts, vv...)
+	}
+
+	seen := make(map[rune]bool)
+	for _, part := range parts {
+		for _, r := range part {
+			if isControlChar(r) && r != '\t' && r != '\n' && r != '\r' {
+				seen[r] = true
+			}
+		}
+	}
+
+	found := make([]string, 0, len(seen))
+	for r := range seen {
+		found = append(found, fmt.Sprintf("0x%02x", r))
+	}
+	sort.Strings(found)
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// ssiDirectiveRe matches server-side include directives like
+// <!--#include virtual="/etc/passwd" --> and <!--#exec cmd="id" -->
+var ssiDirectiveRe = regexp.MustCompile(`(?i)<!--\s*#\s*(include|exec|echo|config|printenv|set|fsize|flastmod|if)\b`)
+
+// ssiInjectionCheck looks for server-side include directives in
+// parameter values, and for the file extensions that get parsed
+// for SSI. SSI's exec directive means that injecting into a page
+// that gets parsed can be RCE. The detail is the extension and
+// param=directive for each parameter with a directive in it.
+func ssiInjectionCheck(u *url.URL) (string, bool) {
+	found := make([]string, 0)
+
+	p := strings.ToLower(u.EscapedPath())
+	for _, e := range []string{".shtml", ".shtm", ".stm"} {
+		if strings.HasSuffix(p, e) {
+			found = append(found, e)
+			break
+		}
+	}
+
+	if d, ok := matchValues(u, ssiDirectiveCheck); ok {
+		found = append(found, d)
+	}
+
+	return strings.Join(found, ","), len(found) > 0
+}
+
+// ssiDirectiveCheck is the per-parameter half of ssiInjectionCheck.
+// Its detail is param=directive.
+func ssiDirectiveCheck(k, v string) (string, bool) {
+	m := ssiDirectiveRe.FindStringSubmatch(v)
+	if m == nil {
+		return "", false
+	}
+	return k + "=" + strings.ToLower(m[1]), true
+}
+
+// adminActionCheck looks for admin areas being told to do something
+// by query string, like /admin/users?action=delete&id=5. On their own
+// the admin path and the parameters are only mildly interesting, but
+// together they're an admin action that can be triggered with a GET,
+// which makes for easy CSRF and a prime authz target. The detail is
+// the admin path segment, then the action or ID parameters.
+func adminActionCheck(u *url.URL) (string, bool) {
+	admin := ""
+	for _, seg := range strings.Split(strings.ToLower(u.EscapedPath()), "/") {
+		switch seg {
+		case "admin", "administrator", "administration", "manage", "management",
+			"manager", "moderator", "moderation", "staff", "backoffice", "cpanel", "controlpanel":
+			admin = seg
+		}
+		if admin != "" {
+			break
+		}
+	}
+	if admin == "" {
+		return "", false
+	}
+
+	found := make([]string, 0)
+	for k, vv := range params(u) {
+		lk := strings.ReplaceAll(strings.ToLower(k), "-", "_")
+
+		switch lk {
+		case "action", "do", "op", "operation", "cmd", "command", "task", "act", "method":
+			for _, v := range vv {
+				if isStateChangingAction(v) {
+					found = append(found, k+"="+strings.ToLower(v))
+					break
+				}
+			}
+
+		case "id", "uid", "user_id", "userid", "account_id", "accountid",
+			"target", "target_id", "targetid", "item_id", "itemid", "ids":
+			found = append(found, k)
+		}
+	}
+	if len(found) == 0 {
+		return "", false
+	}
+	sort.Strings(found)
+
+	return admin + ":" + strings.Join(found, ","), true
+}
+
+// isStateChangingAction returns true if v names an action
+// that changes something, rather than just looking at it
+func isStateChangingAction(v string) bool {
+	v = strings.ReplaceAll(strings.ToLower(v), "-", "_")
+
+	switch v {
+	case "delete", "remove", "destroy", "purge", "drop", "truncate",
+		"ban", "block", "suspend", "disable", "enable", "activate", "deactivate",
+		"approve", "reject", "promote", "demote", "grant", "revoke",
+		"reset", "reset_password", "update", "edit", "save", "create", "add",
+		"impersonate", "sudo", "login_as", "restore", "import", "upload":
+		return true
+	}
+
+	return false
+}
+
+// lastPathSegment returns the last non-empty segment
+// of a URL's path, lowercased
+func lastPathSegment(u *url.URL) string {
+	p := strings.TrimRight(strings.ToLower(u.EscapedPath()), "/")
+
+	if i := strings.LastIndex(p, "/"); i != -1 {
+		return p[i+1:]
+	}
+	return p
+}
+
+// Settings for the high-entropy check
+var (
+	entropyThreshold = 4.5
+	entropyMinLen    = 20
+)
+
+// highEntropyCheck looks for any parameter value that's long and
+// random-looking enough to be a token or key, even if it doesn't
+// match a known secret format. It's noisy, so it's off by default.
+// The detail is param:entropy for each match.
+func highEntropyCheck(k, v string) (string, bool) {
+	if len(v) < entropyMinLen {
+		return "", false
+	}
+
+	e := shannonEntropy(v)
+	return fmt.Sprintf("%s:%.2f", k, e), e >= entropyThreshold
+}
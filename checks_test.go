@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// testURLs are a mix of boring and interesting URLs
+// for exercising the checks as a whole
+var testURLs = []string{
+	"https://example.com/",
+	"https://example.com/about",
+	"https://example.com/index.php?id=1",
+	"https://example.com/admin/users.php?action=delete&id=5",
+	"https://example.com/actuator/env",
+	"https://example.com:8443/debug/pprof/heap",
+	"https://example.com/search?q=1'%20or%201=1--",
+	"https://example.com/redirect?url=http://169.254.169.254/latest/meta-data/",
+	"https://example.com/p?n=4111111111111111&x=1",
+	"https://example.com/x.action?a=%25%7B1%2B1%7D",
+	"https://example.com/api/v1/users;role=admin/list",
+	"https://example.com/file?path=/etc/passwd&debug=true",
+	"https://example.com/q?filter=%7B%22%24where%22%3A%221%22%7D",
+	"https://example.com/img?src=https://127.0.0.1.nip.io/",
+	"https://example.com/x?name=%3C!--%23exec%20cmd%3D%22id%22--%3E",
+	"/admin/config?file=../../etc/passwd",
+	"example.com:8080/jenkins/script",
+	"https://example.com/shop/item?page=2&sort=asc&utm_source=x",
+}
+
+func TestReachesScoreAgreesWithScore(t *testing.T) {
+	orders := map[string][]urlCheck{
+		"default": checks,
+		"weight":  byWeight(checks),
+	}
+
+	for _, raw := range testURLs {
+		u, err := parseURL(raw)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %s", raw, err)
+		}
+
+		s, _ := score(u)
+
+		for name, cc := range orders {
+			for min := 0; min <= s+2; min++ {
+				if got, want := reachesScore(u, min, cc), s >= min; got != want {
+					t.Errorf("%s order, min %d: reachesScore(%s) = %t, but score is %d", name, min, raw, got, s)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkReachesScore(b *testing.B) {
+	// Repeat the test URLs with a bit of variation
+	// so that there's a realistic spread of scores
+	r := rand.New(rand.NewSource(1))
+	uu := make([]*url.URL, 0, 1000)
+	for len(uu) < cap(uu) {
+		raw := testURLs[r.Intn(len(testURLs))]
+		u, err := parseURL(raw)
+		if err != nil {
+			b.Fatal(err)
+		}
+		uu = append(uu, u)
+	}
+
+	orders := []struct {
+		name string
+		cc   []urlCheck
+	}{
+		{"default", checks},
+		{"weight", byWeight(checks)},
+	}
+
+	for _, min := range []int{2, 8} {
+		for _, o := range orders {
+			b.Run(fmt.Sprintf("min=%d/order=%s", min, o.name), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					reachesScore(uu[i%len(uu)], min, o.cc)
+				}
+			})
+		}
+	}
+}
+
+func TestSensitiveValueTypeCards(t *testing.T) {
+	cases := []struct {
+		v    string
+		card bool
+	}{
+		{"4111111111111111", true},
+		{"4012888888881881", true},
+		{"4000056655665556", true},
+		{"2221000000000009", true},
+		{"3530111333300000", true},
+		{"378282246310005", true},
+		{"4111 1111 1111 1111", true},
+
+		// cache-buster timestamps in ms and µs
+		{"1697291544205", false},
+		{"1697291544205123", false},
+		{"1700000000000007", false},
+	}
+
+	for _, c := range cases {
+		typ, _ := sensitiveValueType(c.v)
+		if got := typ == "card-number"; got != c.card {
+			t.Errorf("sensitiveValueType(%q) = %q, want card-number: %v", c.v, typ, c.card)
+		}
+	}
+}
+
+func TestSmugglingIndicatorCRLFInPath(t *testing.T) {
+	cases := []struct {
+		raw  string
+		crlf bool
+	}{
+		{"https://a.com/x%0d%0a", true},
+		{"https://a.com/x%250d%250aSet-Cookie:a=b", true},
+		{"https://a.com/z%5Cr%5Cn", true},
+		{"https://a.com/z%5Cn/y", true},
+		{"https://e.com/docs/..%5Cnode_modules/x", false},
+		{"https://e.com/docs/..%5Cresources%5Cx", false},
+	}
+
+	for _, c := range cases {
+		u, err := parseURL(c.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		detail, _ := smugglingIndicatorCheck(u)
+		if got := strings.Contains(detail, "crlf-in-path"); got != c.crlf {
+			t.Errorf("smugglingIndicatorCheck(%q) = %q, want crlf-in-path: %v", c.raw, detail, c.crlf)
+		}
+	}
+}
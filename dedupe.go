@@ -0,0 +1,242 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// validDedupeMode returns true if mode is
+// one that buildDedupeKey understands
+func validDedupeMode(mode string) bool {
+	switch mode {
+	case "names", "values", "strict":
+		return true
+	}
+	return false
+}
+
+// buildDedupeKey returns a key made up of the hostname, path and
+// parameters of a URL so that only unique requests are output.
+//
+// The mode decides how much of the parameters is used:
+//
+//	names:  just the parameter names
+//	values: the parameter names and their values
+//	strict: the parameter names and the type of their values,
+//	        so ?id=1 and ?id=2 are the same but ?id=abc isn't
+//
+// Names and values are percent-decoded first, so ?name=John%20Doe
+// and ?name=John+Doe have the same key. In values mode, values
+// longer than maxKeyValueLen are replaced with their SHA-256 hash
+// to stop huge payloads eating memory.
+func buildDedupeKey(u *url.URL, mode string) string {
+	// Go's maps aren't ordered, but we want to use all the param names
+	// as part of the key to output only unique requests. To do that, put
+	// them into a slice and then sort it.
+	pp := make([]string, 0)
+	for p, vv := range keyParams(u.RawQuery) {
+		if mode == "names" {
+			pp = append(pp, p)
+			continue
+		}
+
+		for _, v := range vv {
+			switch mode {
+			case "strict":
+				v = valueType(v)
+			case "values":
+				v = shortenValue(v)
+			}
+			pp = append(pp, p+"="+v)
+		}
+	}
+	sort.Strings(pp)
+
+	return fmt.Sprintf("%s%s?%s", hostKey(u), u.EscapedPath(), strings.Join(pp, "&"))
+}
+
+// keyParams splits a raw query string into decoded parameters for
+// use in a dedupe key. Unlike url.ParseQuery it doesn't drop pairs
+// that can't be decoded (e.g. ?q=100%); they're kept as they are so
+// that they still count towards the key.
+func keyParams(rawQuery string) url.Values {
+	pp := make(url.Values)
+
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+
+		k, v := pair, ""
+		if i := strings.Index(pair, "="); i != -1 {
+			k, v = pair[:i], pair[i+1:]
+		}
+
+		if dk, err := url.QueryUnescape(k); err == nil {
+			k = dk
+		}
+		if dv, err := url.QueryUnescape(v); err == nil {
+			v = dv
+		}
+
+		pp[k] = append(pp[k], v)
+	}
+
+	return pp
+}
+
+// hostKey returns the normalised host of a URL for use in a dedupe
+// key. The port is only included when it isn't the default for the
+// scheme, so http://example.com:80/ and http://example.com/ match.
+func hostKey(u *url.URL) string {
+	h := normalizeHost(u.Hostname())
+	if isDefaultPort(u) {
+		return h
+	}
+
+	return net.JoinHostPort(h, u.Port())
+}
+
+// urlID returns a short ID for a dedupe key. The same logical
+// endpoint always gets the same ID, so it can be used to track
+// findings across runs.
+func urlID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:4])
+}
+
+// normalizeHost lowercases a hostname and puts IP addresses into
+// their canonical form, so that equivalent forms of the same IPv6
+// address (e.g. ::1 and 0:0:0:0:0:0:0:1) come out the same
+func normalizeHost(h string) string {
+	h = strings.ToLower(h)
+
+	// net.ParseIP doesn't understand zones like %eth0
+	zone := ""
+	if i := strings.Index(h, "%"); i != -1 {
+		h, zone = h[:i], h[i:]
+	}
+
+	ip := net.ParseIP(h)
+	if ip == nil {
+		return h + zone
+	}
+
+	return ip.String() + zone
+}
+
+// maxKeyValueLen is the longest a parameter value can be
+// before it's hashed in the values dedupe mode
+const maxKeyValueLen = 64
+
+// shortenValue returns v as-is if it's short enough, or
+// its hex-encoded SHA-256 hash if it's not
+func shortenValue(v string) string {
+	if len(v) <= maxKeyValueLen {
+		return v
+	}
+
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
+
+var (
+	intRe    = regexp.MustCompile(`^-?[0-9]+$`)
+	floatRe  = regexp.MustCompile(`^-?[0-9]*\.[0-9]+$`)
+	hexRe    = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	uuidRe   = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	base64Re = regexp.MustCompile(`^[A-Za-z0-9+/_-]{16,}={0,2}$`)
+)
+
+// valueType classifies a parameter value into a rough type
+// token for use in the strict dedupe mode
+func valueType(v string) string {
+	switch {
+	case v == "":
+		return "<empty>"
+	case intRe.MatchString(v):
+		return "<int>"
+	case floatRe.MatchString(v):
+		return "<float>"
+	case uuidRe.MatchString(v):
+		return "<uuid>"
+	case len(v) >= 8 && hexRe.MatchString(v):
+		return "<hex>"
+	case strings.HasPrefix(v, "http://") || strings.HasPrefix(v, "https://"):
+		return "<url>"
+	case base64Re.MatchString(v):
+		return "<base64>"
+	}
+	return "<str>"
+}
+
+// reasonsDedupeKey returns a key made up of the sorted names
+// of the checks that matched a URL, so that one example URL
+// is output per distinct combination of findings
+func reasonsDedupeKey(reasons []reason) string {
+	rr := make([]string, len(reasons))
+	for i, r := range reasons {
+		rr[i] = r.check
+	}
+	sort.Strings(rr)
+
+	return strings.Join(rr, "+")
+}
+
+// keySet keeps track of the dedupe keys that have been seen
+type keySet interface {
+	// seenBefore records key, returning true if it had already been seen
+	seenBefore(key string) bool
+}
+
+// mapKeySet remembers every key forever
+type mapKeySet map[string]bool
+
+func (s mapKeySet) seenBefore(key string) bool {
+	if s[key] {
+		return true
+	}
+	s[key] = true
+	return false
+}
+
+// lruKeySet only remembers the most recently seen keys, which bounds
+// memory use at the cost of letting duplicates through if they're
+// far enough apart in the input
+type lruKeySet struct {
+	size  int
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+func newLRUKeySet(size int) *lruKeySet {
+	return &lruKeySet{
+		size:  size,
+		order: list.New(),
+		keys:  make(map[string]*list.Element),
+	}
+}
+
+func (s *lruKeySet) seenBefore(key string) bool {
+	if e, ok := s.keys[key]; ok {
+		s.order.MoveToFront(e)
+		return true
+	}
+
+	s.keys[key] = s.order.PushFront(key)
+
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.keys, oldest.Value.(string))
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// explainTrace returns a trace of every check's result for a URL,
+// for working out why it scored what it did. Each check gets a line
+// saying whether it matched, didn't match, was skipped because the
+// URL has no host, or is switched off. The final score comes last,
+// which includes anything added on top of the checks (e.g. -rarity
+// and -boost-host).
+func explainTrace(raw string, u *url.URL, final int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "url %s\n", raw)
+
+	for _, c := range checks {
+		switch {
+		case c.off:
+			fmt.Fprintf(&b, "\toff\t%s\n", c.name)
+
+		case c.needsHost && u.Host == "":
+			fmt.Fprintf(&b, "\tskip\t%s\tno host\n", c.name)
+
+		default:
+			if detail, ok := c.match(u); ok {
+				fmt.Fprintf(&b, "\tmatch\t%s\t+%d\t%s\n", c.name, c.weight, detail)
+			} else {
+				fmt.Fprintf(&b, "\tno\t%s\n", c.name)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "score %d", final)
+
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// fetchTimeout bounds how long -fetch waits to connect and to get
+// response headers. There's no limit on reading the body because
+// big URL lists can take a long time to download.
+const fetchTimeout = 30 * time.Second
+
+// fetchInput downloads a list of URLs, returning the body as a stream
+// so that it doesn't all have to be held in memory. Gzipped lists
+// (e.g. urls.txt.gz) are decompressed on the fly.
+func fetchInput(rawURL string) (io.ReadCloser, error) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: fetchTimeout}).DialContext,
+			TLSHandshakeTimeout:   fetchTimeout,
+			ResponseHeaderTimeout: fetchTimeout,
+		},
+	}
+
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return maybeGunzip(resp.Body)
+}
+
+// maybeGunzip wraps r in a gzip reader if it starts
+// with the gzip magic number, or returns it as-is
+func maybeGunzip(r io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return readCloser{br, r}, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	return readCloser{gz, r}, nil
+}
+
+// readCloser reads from one thing but closes another,
+// e.g. reading through a gzip reader that wraps a body
+type readCloser struct {
+	io.Reader
+	c io.Closer
+}
+
+func (rc readCloser) Close() error {
+	return rc.c.Close()
+}
@@ -2,9 +2,14 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
+	"io"
+	"math"
 	"net/url"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -16,170 +21,767 @@ import (
 //   dev/stage/test in path or hostname
 //   jenkins, graphite etc in hostname or path
 
-type urlCheck func(*url.URL) bool
-
 func main() {
 
-	checks := []urlCheck{
-		// query string stuff
-		func(u *url.URL) bool {
+	var verbose bool
+	flag.BoolVar(&verbose, "v", false, "show the score and the reasons each URL is interesting")
+
+	var minScore int
+	flag.IntVar(&minScore, "min", 1, "minimum score for a URL to be output")
+
+	var dedupeMode string
+	flag.StringVar(&dedupeMode, "dedupe-mode", "names", "what to dedupe URLs on along with the host and path: names, values or strict (parameter names and value types)")
+
+	var dedupeWindow int
+	flag.IntVar(&dedupeWindow, "dedupe-window", 0, "only remember the last N dedupe keys to bound memory use (0 remembers everything)")
+
+	var dedupeByReasons bool
+	flag.BoolVar(&dedupeByReasons, "dedupe-by-reasons", false, "output only one URL for each distinct combination of reasons")
+
+	var decodeOutput bool
+	flag.BoolVar(&decodeOutput, "decode-output", false, "output URLs with their path and query string percent-decoded")
+
+	var withID bool
+	flag.BoolVar(&withID, "with-id", false, "prefix each line of output with a short ID that's stable for the same endpoint across runs")
+
+	var excludeRegex string
+	flag.StringVar(&excludeRegex, "exclude-regex", "", "ignore URLs matching this regular expression")
+
+	var fuzzFormat bool
+	flag.BoolVar(&fuzzFormat, "fuzz-format", false, "output a fuzzing template for each interesting parameter instead of the URL")
+
+	var fuzzPlaceholder string
+	flag.StringVar(&fuzzPlaceholder, "fuzz-placeholder", "FUZZ", "the placeholder used by -fuzz-format")
+
+	var entropy bool
+	flag.BoolVar(&entropy, "entropy", false, "enable the high-entropy check for random-looking parameter values")
+
+	flag.Float64Var(&entropyThreshold, "entropy-threshold", entropyThreshold, "minimum Shannon entropy (bits per character) for the high-entropy check")
+	flag.IntVar(&entropyMinLen, "entropy-min-len", entropyMinLen, "minimum value length for the high-entropy check")
+
+	var self string
+	flag.StringVar(&self, "self", "", "comma separated parameter values (IDs, usernames etc) that belong to you, ignored by the horizontal-authz check")
+
+	var rejectPartial bool
+	flag.BoolVar(&rejectPartial, "reject-partial", false, "drop URLs that look truncated (reported on stderr with -v)")
+
+	var burp bool
+	flag.BoolVar(&burp, "burp", false, "output results as Burp Suite saved-items XML")
+
+	var jsonArray bool
+	flag.BoolVar(&jsonArray, "json-array", false, "output results as a single JSON array")
+
+	var boosts hostBoosts
+	flag.Var(&boosts, "boost-host", "multiply the score of URLs on hosts matching a pattern, e.g. *.internal.example.com:2 (can be given more than once)")
+
+	var suggestMin bool
+	flag.BoolVar(&suggestMin, "suggest-min", false, "suggest a -min value that keeps the top 10% of URLs (printed on stderr)")
+
+	var inputFormat string
+	flag.StringVar(&inputFormat, "input-format", "url", "what each line of input is: url, or form for URL-encoded POST bodies like a=1&b=2")
+
+	var rarity bool
+	flag.BoolVar(&rarity, "rarity", false, "add a bonus to the score of URLs with parameter values that only appear once in the input (holds the whole input in memory)")
+
+	var rarityBonus int
+	flag.IntVar(&rarityBonus, "rarity-bonus", 1, "the score bonus used by -rarity")
+
+	var explainAll bool
+	flag.BoolVar(&explainAll, "explain-all", false, "write the result of every check for every URL to -explain-file, for debugging scores")
+
+	var explainFile string
+	flag.StringVar(&explainFile, "explain-file", "", "where -explain-all writes its traces (default stderr)")
+
+	var unwrap bool
+	flag.BoolVar(&unwrap, "unwrap", false, "also score URLs found in parameters of the input URLs, e.g. ?u=https%3A%2F%2Fexample.com%2Fadmin")
+
+	var fetch string
+	flag.StringVar(&fetch, "fetch", "", "read URLs from this http(s) URL instead of stdin (gzipped lists are fine)")
+
+	var fieldList string
+	flag.StringVar(&fieldList, "fields", "", "comma separated fields to output, in order, instead of the usual format: id, score, url, reasons, boost")
+
+	var delimiter string
+	flag.StringVar(&delimiter, "delimiter", "\t", "the delimiter between the fields given with -fields")
+
+	var hideReasons string
+	flag.StringVar(&hideReasons, "hide-reasons", "", "comma separated checks (or rare-value, nested) to leave out of the reasons shown; they still count towards the score")
+
+	var preserveStructure bool
+	flag.BoolVar(&preserveStructure, "preserve-structure", false, "pass # comment lines and blank lines through to the output unchanged")
+
+	var orderChecks bool
+	flag.BoolVar(&orderChecks, "order-checks", false, "run the heaviest checks first when only filtering URLs")
+
+	var profile string
+	flag.StringVar(&profile, "profile", "", "load checks, weights and settings from a profile file (flags given on the command line win)")
+
+	var showChecks bool
+	flag.BoolVar(&showChecks, "list-checks", false, "list the name, default weight, default state and description of every check and exit")
+
+	var showVersion bool
+	flag.BoolVar(&showVersion, "version", false, "print version and build information and exit")
 
-			interesting := 0
-			for k, vv := range u.Query() {
-				for _, v := range vv {
-					if qsCheck(k, v) {
-						interesting++
+	flag.Parse()
+
+	if showVersion {
+		printVersion(os.Stdout)
+		return
+	}
+
+	if profile != "" {
+		if err := loadProfile(profile); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load profile: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if showChecks {
+		listChecks(os.Stdout)
+		return
+	}
+
+	if entropy {
+		enableCheck("high-entropy")
+	}
+
+	for _, v := range strings.Split(self, ",") {
+		if v != "" {
+			selfValues[v] = true
+		}
+	}
+
+	if !validDedupeMode(dedupeMode) {
+		fmt.Fprintf(os.Stderr, "invalid dedupe mode: %s\n", dedupeMode)
+		os.Exit(1)
+	}
+
+	if dedupeWindow < 0 {
+		fmt.Fprintf(os.Stderr, "invalid dedupe window: %d\n", dedupeWindow)
+		os.Exit(1)
+	}
+
+	if inputFormat != "url" && inputFormat != "form" {
+		fmt.Fprintf(os.Stderr, "invalid input format: %s\n", inputFormat)
+		os.Exit(1)
+	}
+
+	fields, err := parseFields(fieldList)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid fields: %s\n", err)
+		os.Exit(1)
+	}
+
+	// These all replace the normal output, so they can't be mixed
+	formats := 0
+	for _, on := range []bool{burp, jsonArray, fuzzFormat, len(fields) > 0} {
+		if on {
+			formats++
+		}
+	}
+	if formats > 1 {
+		fmt.Fprintln(os.Stderr, "only one of -burp, -json-array, -fuzz-format and -fields can be used at once")
+		os.Exit(1)
+	}
+	delimiter = unescapeDelimiter(delimiter)
+
+	hidden := make(map[string]bool)
+	for _, name := range strings.Split(hideReasons, ",") {
+		if name == "" {
+			continue
+		}
+		if !isReason(name) {
+			fmt.Fprintf(os.Stderr, "unknown reason in -hide-reasons: %s\n", name)
+			os.Exit(1)
+		}
+		hidden[name] = true
+	}
+
+	var exclude *regexp.Regexp
+	if excludeRegex != "" {
+		exclude, err = regexp.Compile(excludeRegex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid exclude regex: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// When nothing needs the full score or the reasons we can take a
+	// faster path that stops checking a URL as soon as it's reached
+	// the minimum score
+	compact := !verbose && !dedupeByReasons && !burp && len(boosts) == 0 && !suggestMin && len(fields) == 0 && !rarity && !explainAll && !jsonArray
+
+	// Full scoring always uses the normal order so that
+	// the reasons come out the same way every time
+	ordered := checks
+	if compact && orderChecks {
+		ordered = byWeight(checks)
+	}
+
+	scores := make([]int, 0)
+
+	out := newOutputWriter(os.Stdout)
+
+	if burp {
+		out.println(burpHeader())
+	}
+
+	var ja *jsonArrayWriter
+	if jsonArray {
+		ja = newJSONArrayWriter(out)
+	}
+
+	var explain *outputWriter
+	if explainAll {
+		w := os.Stderr
+		if explainFile != "" {
+			f, err := os.Create(explainFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to create explain file: %s\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			w = f
+		}
+		explain = newOutputWriter(w)
+	}
+
+	var seen keySet = make(mapKeySet)
+	if dedupeWindow > 0 {
+		seen = newLRUKeySet(dedupeWindow)
+	}
+
+	var input io.Reader = os.Stdin
+	if fetch != "" {
+		body, err := fetchInput(fetch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to fetch %s: %s\n", fetch, err)
+			os.Exit(1)
+		}
+		defer body.Close()
+		input = body
+	}
+
+	parse := parseURL
+	if inputFormat == "form" {
+		parse = parseFormBody
+	}
+
+	// Rarity needs to see every value before it can score anything,
+	// so the input is kept in memory while it's counted and then
+	// read a second time from there
+	var rare valueCounts
+	if rarity {
+		var buf bytes.Buffer
+		rare, err = countValues(io.TeeReader(input, &buf), parse)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read input: %s\n", err)
+			os.Exit(1)
+		}
+		input = &buf
+	}
+
+	sc := bufio.NewScanner(input)
+	for sc.Scan() {
+
+		// Comments and blank lines can't go in Burp's XML or in JSON
+		if preserveStructure && !burp && !jsonArray && isStructureLine(sc.Text()) {
+			out.println(sc.Text())
+			continue
+		}
+
+		// With -unwrap, URLs found in parameters are queued up
+		// to go through everything after the one they came from
+		queue := []inputLine{{raw: sc.Text()}}
+		for len(queue) > 0 {
+			in := queue[0]
+			queue = queue[1:]
+
+			if exclude != nil && exclude.MatchString(in.raw) {
+				continue
+			}
+
+			p := parse
+			if in.depth > 0 {
+				p = parseURL
+			}
+
+			u, err := p(in.raw)
+			if err != nil {
+				//fmt.Fprintf(os.Stderr, "failed to parse url %s [%s]\n", in.raw, err)
+				continue
+			}
+
+			// Look inside even if this URL ends up being dropped;
+			// the wrapper is often boring while what it wraps isn't
+			if unwrap && in.depth < maxUnwrapDepth {
+				queue = append(queue, nestedURLs(u, in.depth+1)...)
+			}
+
+			if rejectPartial {
+				if why := partialReason(in.raw, u); why != "" {
+					if verbose {
+						fmt.Fprintf(os.Stderr, "rejected partial url %s [%s]\n", in.raw, why)
 					}
+					continue
 				}
 			}
-			return interesting > 0
-		},
-
-		// extensions
-		func(u *url.URL) bool {
-			exts := []string{
-				".php",
-				".phtml",
-				".asp",
-				".aspx",
-				".asmx",
-				".ashx",
-				".cgi",
-				".pl",
-				".json",
-				".xml",
-				".rb",
-				".py",
-				".sh",
-				".yaml",
-				".yml",
-				".toml",
-				".ini",
-				".md",
-				".mkd",
-				".do",
-				".jsp",
-				".jspa",
-			}
-
-			p := strings.ToLower(u.EscapedPath())
-			for _, e := range exts {
-				if strings.HasSuffix(p, e) {
-					return true
+
+			if isBoringStaticFile(u) {
+				continue
+			}
+
+			// Only output each host + path + params combination once,
+			// unless we're deduping on the reasons instead
+			key := buildDedupeKey(u, dedupeMode)
+			if !dedupeByReasons && seen.seenBefore(key) {
+				continue
+			}
+
+			var s int
+			var reasons []reason
+			boost := 1.0
+
+			if compact {
+				if !reachesScore(u, minScore, ordered) {
+					continue
+				}
+			} else {
+				s, reasons = score(u)
+
+				if rare != nil {
+					if pp := rare.rareParams(u); len(pp) > 0 {
+						s += rarityBonus
+						reasons = append(reasons, reason{"rare-value", strings.Join(pp, ",")})
+					}
+				}
+
+				s, boost = boosts.apply(s, u.Hostname())
+
+				if in.from != "" {
+					reasons = append(reasons, reason{"nested", in.from})
+				}
+
+				if explain != nil {
+					explain.println(explainTrace(in.raw, u, s))
+				}
+
+				if suggestMin {
+					scores = append(scores, s)
+				}
+
+				if s < minScore {
+					continue
 				}
 			}
 
-			return false
-		},
-
-		// path bits
-		func(u *url.URL) bool {
-			p := strings.ToLower(u.EscapedPath())
-			return strings.Contains(p, "ajax") ||
-				strings.Contains(p, "jsonp") ||
-				strings.Contains(p, "admin") ||
-				strings.Contains(p, "include") ||
-				strings.Contains(p, "src") ||
-				strings.Contains(p, "redirect") ||
-				strings.Contains(p, "proxy") ||
-				strings.Contains(p, "test") ||
-				strings.Contains(p, "tmp") ||
-				strings.Contains(p, "temp")
-		},
-
-		// non-standard port
-		func(u *url.URL) bool {
-			return (u.Port() != "80" && u.Port() != "443" && u.Port() != "")
-		},
-	}
-
-	seen := make(map[string]bool)
-
-	sc := bufio.NewScanner(os.Stdin)
-	for sc.Scan() {
+			if dedupeByReasons && seen.seenBefore(reasonsDedupeKey(reasons)) {
+				continue
+			}
 
-		u, err := url.Parse(sc.Text())
-		if err != nil {
-			//fmt.Fprintf(os.Stderr, "failed to parse url %s [%s]\n", sc.Text(), err)
-			continue
+			// Hidden reasons still count towards the score and the
+			// reasons dedupe, they just aren't shown
+			if len(hidden) > 0 {
+				reasons = filterReasons(reasons, hidden)
+			}
+
+			if burp {
+				// Burp can't do anything with a URL that has no host
+				if u.Host != "" {
+					out.println(burpItem(u, formatReasons(reasons)))
+				}
+				continue
+			}
+
+			if fuzzFormat {
+				for _, t := range fuzzTemplates(u, interestingParams(u), fuzzPlaceholder) {
+					// Form bodies don't have the ? that
+					// comes with the URL they're parsed into
+					if inputFormat == "form" {
+						t = strings.TrimPrefix(t, "?")
+					}
+					out.println(t)
+				}
+				continue
+			}
+
+			line := in.raw
+			if decodeOutput {
+				line = decodedURL(u)
+				if inputFormat == "form" {
+					line = strings.TrimPrefix(line, "?")
+				}
+			}
+
+			if ja != nil {
+				if err := ja.write(newJSONResult(urlID(key), line, s, reasons)); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to encode %s as JSON: %s\n", line, err)
+				}
+				continue
+			}
+
+			if len(fields) > 0 {
+				out.println(formatFields(fields, map[string]string{
+					"id":      urlID(key),
+					"score":   fmt.Sprint(s),
+					"url":     line,
+					"reasons": formatReasons(reasons),
+					"boost":   fmt.Sprintf("%g", boost),
+				}, delimiter))
+				continue
+			}
+
+			if verbose {
+				line = fmt.Sprintf("%d %s [%s]", s, line, formatReasons(reasons))
+				if boost != 1 {
+					line += fmt.Sprintf(" boost(x%g)", boost)
+				}
+			}
+
+			if withID {
+				line = urlID(key) + " " + line
+			}
+
+			out.println(line)
 		}
 
-		if isBoringStaticFile(u) {
+	}
+
+	if burp {
+		out.println(burpFooter())
+	}
+
+	if ja != nil {
+		ja.close()
+	}
+
+	out.close()
+
+	if explain != nil {
+		explain.close()
+	}
+
+	// A download that fails part way through (or a corrupt gzip)
+	// just ends the input early, so make sure that doesn't go
+	// unnoticed
+	if err := sc.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read input: %s\n", err)
+		os.Exit(1)
+	}
+
+	if suggestMin {
+		min, kept := suggestMinScore(scores, suggestTopFraction)
+		fmt.Fprintf(os.Stderr, "suggested -min: %d (keeps %d of %d URLs)\n", min, kept, len(scores))
+	}
+
+}
+
+// parseURL parses a line of input as a URL. As well as full URLs
+// it accepts path-only input (e.g. /admin?id=1) and host:port
+// input without a scheme, which url.Parse would otherwise treat
+// as an opaque URL with the hostname as its scheme.
+//
+// url.Parse refuses raw control characters, so they're percent-encoded
+// first; that way the control-char check still gets to see the URL.
+func parseURL(raw string) (*url.URL, error) {
+	raw = escapeControlChars(raw)
+	u, err := url.Parse(raw)
+
+	if (err != nil || u.Opaque != "") && looksLikeHostPort(raw) {
+		return url.Parse("//" + raw)
+	}
+
+	return u, err
+}
+
+// inputLine is a line of input to be scored. URLs unwrapped from the
+// parameters of another URL have the name of the parameter they came
+// from and how deeply they were nested.
+type inputLine struct {
+	raw   string
+	from  string
+	depth int
+}
+
+// maxUnwrapDepth is how many levels of URL-in-a-parameter
+// -unwrap will dig through
+const maxUnwrapDepth = 3
+
+// nestedURLs returns the http(s) URLs in the parameters of u as
+// inputLines at the given depth, sorted by parameter name
+func nestedURLs(u *url.URL, depth int) []inputLine {
+	out := make([]inputLine, 0)
+
+	for k, vv := range params(u) {
+		for _, v := range vv {
+			inner, err := url.Parse(strings.TrimSpace(v))
+			if err != nil || inner.Host == "" {
+				continue
+			}
+
+			switch strings.ToLower(inner.Scheme) {
+			case "http", "https":
+				out = append(out, inputLine{strings.TrimSpace(v), k, depth})
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].from < out[j].from
+	})
+
+	return out
+}
+
+// parseFormBody parses a line of input as an URL-encoded POST body,
+// e.g. a=1&b=2, returning a URL with no host or path and the body as
+// its query string. That way the parameter checks can run against
+// body parameters just like they do against query strings.
+func parseFormBody(raw string) (*url.URL, error) {
+	raw = strings.TrimPrefix(raw, "?")
+
+	if !strings.Contains(raw, "=") {
+		return nil, fmt.Errorf("no parameters in form body")
+	}
+	if _, err := url.ParseQuery(raw); err != nil {
+		return nil, err
+	}
+
+	return &url.URL{RawQuery: raw}, nil
+}
+
+// escapeControlChars percent-encodes any ASCII control characters in s
+func escapeControlChars(s string) string {
+	if strings.IndexFunc(s, isControlChar) == -1 {
+		return s
+	}
+
+	var b strings.Builder
+	for _, r := range s {
+		if isControlChar(r) {
+			fmt.Fprintf(&b, "%%%02X", r)
 			continue
 		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
 
-		// Go's maps aren't ordered, but we want to use all the param names
-		// as part of the key to output only unique requests. To do that, put
-		// them into a slice and then sort it.
-		pp := make([]string, 0)
-		for p, _ := range u.Query() {
-			pp = append(pp, p)
+// isControlChar returns true for ASCII control characters, tab included
+func isControlChar(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
+// looksLikeHostPort returns true if raw starts with something
+// like example.com:8080 rather than a scheme like mailto:
+func looksLikeHostPort(raw string) bool {
+	i := strings.Index(raw, ":")
+	if i < 1 {
+		return false
+	}
+
+	host := raw[:i]
+	if !strings.Contains(host, ".") && host != "localhost" {
+		return false
+	}
+
+	port := raw[i+1:]
+	if j := strings.IndexAny(port, "/?#"); j != -1 {
+		port = port[:j]
+	}
+	if port == "" {
+		return false
+	}
+	for _, r := range port {
+		if r < '0' || r > '9' {
+			return false
 		}
-		sort.Strings(pp)
+	}
+
+	return true
+}
 
-		key := fmt.Sprintf("%s%s?%s", u.Hostname(), u.EscapedPath(), strings.Join(pp, "&"))
+// suggestTopFraction is the fraction of URLs
+// that -suggest-min tries to keep
+const suggestTopFraction = 0.1
 
-		// Only output each host + path + params combination once
-		if _, exists := seen[key]; exists {
-			continue
+// suggestMinScore returns the minimum score that keeps roughly the
+// top fraction of the given scores, along with how many scores it
+// actually keeps. URLs with the same score are kept or dropped
+// together, so it can keep more than the fraction asked for.
+func suggestMinScore(scores []int, fraction float64) (int, int) {
+	if len(scores) == 0 {
+		return 1, 0
+	}
+
+	sorted := make([]int, len(scores))
+	copy(sorted, scores)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	i := int(math.Ceil(float64(len(sorted))*fraction)) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	min := sorted[i]
+	if min < 1 {
+		min = 1
+	}
+
+	kept := 0
+	for _, s := range sorted {
+		if s >= min {
+			kept++
 		}
-		seen[key] = true
+	}
 
-		interesting := 0
+	return min, kept
+}
 
-		for _, check := range checks {
-			if check(u) {
-				interesting++
-			}
+// isStructureLine returns true for blank lines and # comments,
+// which annotated URL lists use to organise themselves
+func isStructureLine(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// partialReason returns why a URL looks like it's been truncated or
+// split across lines, or an empty string if it looks complete.
+// url.Parse is happy to accept most truncated input, so this looks
+// for the tell-tale signs instead.
+func partialReason(raw string, u *url.URL) string {
+	// A percent-encoding cut off part way through, like %2 or %
+	if i := strings.LastIndex(raw, "%"); i != -1 && len(raw)-i < 3 {
+		return "incomplete percent-encoding"
+	}
+
+	// Parentheses and braces are left out here because SQLi and
+	// template injection payloads (e.g. {{7*7}) are often
+	// deliberately unbalanced, but a brace opened right at the
+	// end of the line is still a giveaway
+	if strings.Count(raw, "[") != strings.Count(raw, "]") {
+		return "unbalanced []"
+	}
+	if strings.HasSuffix(raw, "{") {
+		return "unclosed { at end"
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ws", "wss", "ftp":
+		if u.Host == "" {
+			return "missing host"
+		}
+	}
+
+	return ""
+}
+
+// formatReasons returns the reasons a URL matched as a
+// space separated string
+func formatReasons(reasons []reason) string {
+	rr := make([]string, len(reasons))
+	for i, r := range reasons {
+		rr[i] = r.String()
+	}
+
+	return strings.Join(rr, " ")
+}
+
+// extraReasons are the reasons that can be given for
+// a URL that don't come from a check
+var extraReasons = []string{"rare-value", "nested"}
+
+// isReason returns true if name is a check
+// or one of the extra reasons
+func isReason(name string) bool {
+	if isCheck(name) {
+		return true
+	}
+	for _, r := range extraReasons {
+		if r == name {
+			return true
 		}
+	}
+	return false
+}
 
-		if interesting > 0 {
-			fmt.Println(sc.Text())
+// filterReasons returns the reasons for
+// checks that aren't in hidden
+func filterReasons(reasons []reason, hidden map[string]bool) []reason {
+	out := make([]reason, 0, len(reasons))
+	for _, r := range reasons {
+		if !hidden[r.check] {
+			out = append(out, r)
 		}
+	}
+	return out
+}
+
+// decodedURL returns a more human-readable form of a URL, with
+// the path and query string percent-decoded. If either of them
+// can't be decoded it is left as it was.
+func decodedURL(u *url.URL) string {
+	p, err := url.PathUnescape(u.EscapedPath())
+	if err != nil {
+		p = u.EscapedPath()
+	}
 
+	q, err := url.QueryUnescape(u.RawQuery)
+	if err != nil {
+		q = u.RawQuery
+	}
+
+	// Let the url package deal with putting the scheme,
+	// userinfo and host back together for us
+	base := *u
+	base.Path = ""
+	base.RawPath = ""
+	base.RawQuery = ""
+	base.ForceQuery = false
+	base.Fragment = ""
+	base.RawFragment = ""
+
+	out := base.String() + p
+	if u.RawQuery != "" || u.ForceQuery {
+		out += "?" + q
+	}
+	if u.Fragment != "" {
+		out += "#" + u.Fragment
 	}
 
+	return out
 }
 
-// qsCheck looks a key=value pair from a query
-// string and returns true if it looks interesting
-func qsCheck(k, v string) bool {
-	k = strings.ToLower(k)
-	v = strings.ToLower(v)
+// fuzzTemplates returns a copy of the URL for each of the named
+// parameters, with that parameter's value replaced by placeholder.
+// Everything else in the query string is left exactly as it was.
+func fuzzTemplates(u *url.URL, params []string, placeholder string) []string {
+	out := make([]string, 0, len(params))
 
-	// the super-common utm_referrer etc
-	// are rarely interesting
-	if strings.HasPrefix(k, "utm_") {
-		return false
+	for _, p := range params {
+		pairs := strings.Split(u.RawQuery, "&")
+		for i, pair := range pairs {
+			k := pair
+			if j := strings.Index(pair, "="); j != -1 {
+				k = pair[:j]
+			}
+
+			dk, err := url.QueryUnescape(k)
+			if err != nil {
+				dk = k
+			}
+
+			if dk == p {
+				pairs[i] = k + "=" + placeholder
+			}
+		}
+
+		t := *u
+		t.RawQuery = strings.Join(pairs, "&")
+		out = append(out, t.String())
 	}
 
-	// value checks
-	return strings.HasPrefix(v, "http") ||
-		strings.Contains(v, "{") ||
-		strings.Contains(v, "[") ||
-		strings.Contains(v, "/") ||
-		strings.Contains(v, "\\") ||
-		strings.Contains(v, "<") ||
-		strings.Contains(v, "(") ||
-		// shoutout to liveoverflow ;)
-		strings.Contains(v, "eyj") ||
-
-		// key checks
-		strings.Contains(k, "redirect") ||
-		strings.Contains(k, "debug") ||
-		strings.Contains(k, "password") ||
-		strings.Contains(k, "passwd") ||
-		strings.Contains(k, "file") ||
-		strings.Contains(k, "fn") ||
-		strings.Contains(k, "template") ||
-		strings.Contains(k, "include") ||
-		strings.Contains(k, "require") ||
-		strings.Contains(k, "url") ||
-		strings.Contains(k, "uri") ||
-		strings.Contains(k, "src") ||
-		strings.Contains(k, "href") ||
-		strings.Contains(k, "func") ||
-		strings.Contains(k, "callback")
+	return out
 }
 
 func isBoringStaticFile(u *url.URL) bool {
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// outputWriter serialises output through a single goroutine
+// so that lines never interleave, no matter how many
+// goroutines are writing at once
+type outputWriter struct {
+	lines chan string
+	done  chan struct{}
+}
+
+// newOutputWriter returns an outputWriter that writes to w.
+// It must be closed to make sure everything gets written.
+func newOutputWriter(w io.Writer) *outputWriter {
+	ow := &outputWriter{
+		lines: make(chan string, 256),
+		done:  make(chan struct{}),
+	}
+
+	go func() {
+		bw := bufio.NewWriter(w)
+		for l := range ow.lines {
+			bw.WriteString(l)
+			bw.WriteByte('\n')
+
+			// Only flush when there's nothing else waiting so that
+			// we get the benefit of buffering on big inputs without
+			// holding output back when the input is slow
+			if len(ow.lines) == 0 {
+				bw.Flush()
+			}
+		}
+		bw.Flush()
+		close(ow.done)
+	}()
+
+	return ow
+}
+
+// println queues a line of output. It is safe to call
+// from multiple goroutines.
+func (ow *outputWriter) println(line string) {
+	ow.lines <- line
+}
+
+// close waits for all queued output to be written
+func (ow *outputWriter) close() {
+	close(ow.lines)
+	<-ow.done
+}
+
+// outputFields are the fields that can be asked for with -fields
+var outputFields = []string{"id", "score", "url", "reasons", "boost"}
+
+// parseFields parses a comma separated list of output field names,
+// making sure they're all ones we know about
+func parseFields(s string) ([]string, error) {
+	fields := make([]string, 0)
+
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(strings.ToLower(f))
+		if f == "" {
+			continue
+		}
+
+		known := false
+		for _, of := range outputFields {
+			if f == of {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown field %s (want %s)", f, strings.Join(outputFields, ", "))
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// unescapeDelimiter turns the escape sequences people are likely
+// to type on the command line (e.g. -delimiter '\t') into the
+// characters they stand for
+func unescapeDelimiter(d string) string {
+	return strings.NewReplacer(`\t`, "\t", `\n`, "\n", `\\`, `\`).Replace(d)
+}
+
+// formatFields returns the values of the given fields
+// in the order they were asked for, joined by delim
+func formatFields(fields []string, values map[string]string, delim string) string {
+	out := make([]string, len(fields))
+	for i, f := range fields {
+		out[i] = values[f]
+	}
+	return strings.Join(out, delim)
+}
+
+// jsonResult is how a URL is written by -json-array
+type jsonResult struct {
+	ID      string       `json:"id"`
+	URL     string       `json:"url"`
+	Score   int          `json:"score"`
+	Reasons []jsonReason `json:"reasons"`
+}
+
+type jsonReason struct {
+	Check  string `json:"check"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// newJSONResult returns the jsonResult for a URL
+func newJSONResult(id, u string, score int, reasons []reason) jsonResult {
+	jr := make([]jsonReason, len(reasons))
+	for i, r := range reasons {
+		jr[i] = jsonReason{r.check, r.detail}
+	}
+	return jsonResult{id, u, score, jr}
+}
+
+// jsonArrayWriter streams values out as a single JSON array, one
+// element per line. Each element is held back until the next one
+// comes along so that it knows whether it needs a trailing comma,
+// which means nothing else has to be buffered.
+type jsonArrayWriter struct {
+	out     *outputWriter
+	pending string
+}
+
+func newJSONArrayWriter(out *outputWriter) *jsonArrayWriter {
+	return &jsonArrayWriter{out: out}
+}
+
+// write adds v to the array
+func (j *jsonArrayWriter) write(v interface{}) error {
+	// Escaping HTML would turn every & in a URL into \u0026
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	if j.pending == "" {
+		j.out.println("[")
+	} else {
+		j.out.println(j.pending + ",")
+	}
+	j.pending = strings.TrimSuffix(b.String(), "\n")
+
+	return nil
+}
+
+// close finishes the array off. If nothing was
+// written it comes out as an empty array.
+func (j *jsonArrayWriter) close() {
+	if j.pending == "" {
+		j.out.println("[]")
+		return
+	}
+
+	j.out.println(j.pending)
+	j.out.println("]")
+}
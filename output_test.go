@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestOutputWriterNoInterleaving(t *testing.T) {
+	const (
+		writers = 64
+		lines   = 500
+	)
+
+	var buf bytes.Buffer
+	ow := newOutputWriter(&buf)
+
+	// Long lines make interleaving more likely to show up
+	pad := strings.Repeat("x", 200)
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for l := 0; l < lines; l++ {
+				ow.println(fmt.Sprintf("%d:%d:%s", w, l, pad))
+			}
+		}(w)
+	}
+	wg.Wait()
+	ow.close()
+
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var w, l int
+		var rest string
+		if _, err := fmt.Sscanf(line, "%d:%d:%s", &w, &l, &rest); err != nil || rest != pad {
+			t.Fatalf("mangled line: %q", line)
+		}
+
+		key := fmt.Sprintf("%d:%d", w, l)
+		if seen[key] {
+			t.Fatalf("duplicate line: %s", key)
+		}
+		seen[key] = true
+	}
+
+	if len(seen) != writers*lines {
+		t.Errorf("want %d lines, got %d", writers*lines, len(seen))
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// loadProfile reads a detection profile and applies it. A profile
+// is a text file with one setting per line; blank lines and lines
+// starting with # are ignored:
+//
+//	# sqli-focus.profile
+//	enable high-entropy
+//	disable query-params
+//	weight sqli-in-path 4
+//	min 3
+//
+// enable, disable and weight configure checks. Anything else is the
+// name of a command-line flag and its value (a bool flag on its own
+// is switched on). Flags given on the command line win over the
+// profile, so flag.Parse must have been called first.
+func loadProfile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	n := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		n++
+
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := applyProfileLine(line, explicit); err != nil {
+			return fmt.Errorf("%s:%d: %s", path, n, err)
+		}
+	}
+
+	return sc.Err()
+}
+
+// applyProfileLine applies a single profile setting,
+// skipping flags that are in explicit
+func applyProfileLine(line string, explicit map[string]bool) error {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "enable", "disable":
+		if len(args) != 1 {
+			return fmt.Errorf("%s needs a check name", name)
+		}
+		if !isCheck(args[0]) {
+			return fmt.Errorf("unknown check %s", args[0])
+		}
+
+		if name == "enable" {
+			enableCheck(args[0])
+		} else {
+			disableCheck(args[0])
+		}
+		return nil
+
+	case "weight":
+		if len(args) != 2 {
+			return fmt.Errorf("weight needs a check name and a weight")
+		}
+		if !isCheck(args[0]) {
+			return fmt.Errorf("unknown check %s", args[0])
+		}
+
+		// The fast path in reachesScore relies on
+		// weights never being negative
+		w, err := strconv.Atoi(args[1])
+		if err != nil || w < 0 {
+			return fmt.Errorf("invalid weight %s", args[1])
+		}
+		setCheckWeight(args[0], w)
+		return nil
+
+	case "profile":
+		return fmt.Errorf("profiles can't load other profiles")
+	}
+
+	f := flag.Lookup(name)
+	if f == nil {
+		return fmt.Errorf("unknown setting %s", name)
+	}
+
+	if explicit[name] {
+		return nil
+	}
+
+	v := strings.Join(args, " ")
+	if v == "" {
+		if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && bf.IsBoolFlag() {
+			v = "true"
+		}
+	}
+
+	if err := flag.Set(name, v); err != nil {
+		return fmt.Errorf("invalid value for %s: %s", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"sort"
+)
+
+// valueCounts counts how many times each parameter value turns up
+// across the whole input, keyed on the parameter name and value
+type valueCounts map[string]int
+
+// countValues reads every line of r, counting the parameter values
+// of those that parse. Empty values aren't counted because they're
+// never interesting on their own.
+func countValues(r io.Reader, parse func(string) (*url.URL, error)) (valueCounts, error) {
+	vc := make(valueCounts)
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		u, err := parse(sc.Text())
+		if err != nil {
+			continue
+		}
+
+		for k, vv := range params(u) {
+			for _, v := range vv {
+				if v != "" {
+					vc[k+"="+v]++
+				}
+			}
+		}
+	}
+
+	return vc, sc.Err()
+}
+
+// rareParams returns the sorted names of the parameters in
+// a URL that have a value that was only seen once
+func (vc valueCounts) rareParams(u *url.URL) []string {
+	keys := make([]string, 0)
+
+	for k, vv := range params(u) {
+		for _, v := range vv {
+			if v != "" && vc[k+"="+v] == 1 {
+				keys = append(keys, k)
+				break
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
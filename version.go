@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// version is set at build time with:
+//
+//	go build -ldflags "-X main.version=v1.2.3"
+//
+// When it isn't, the module version from the build info is
+// used instead (e.g. when installed with go install).
+var version = ""
+
+// printVersion writes the version of the tool
+// along with some information about the build
+func printVersion(w io.Writer) {
+	bi, ok := debug.ReadBuildInfo()
+
+	v := version
+	if v == "" && ok && bi.Main.Version != "" {
+		v = bi.Main.Version
+	}
+	if v == "" {
+		v = "(devel)"
+	}
+
+	fmt.Fprintf(w, "urinteresting %s\n", v)
+
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "go: %s\n", bi.GoVersion)
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified", "GOOS", "GOARCH":
+			fmt.Fprintf(w, "%s: %s\n", s.Key, s.Value)
+		}
+	}
+}